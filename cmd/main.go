@@ -8,26 +8,222 @@
 package main
 
 import (
-	"go-api-server/internal/database" // Import the database package
-	"go-api-server/internal/handler"  // Import the handler package
-	"go-api-server/internal/router"   // Import the router package
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"go-api-server/internal/config"    // Import the config package
+	"go-api-server/internal/database"  // Import the database package
+	"go-api-server/internal/exchange"  // Import the exchange-rate package
+	"go-api-server/internal/handler"   // Import the handler package
+	"go-api-server/internal/router"    // Import the router package
+	"go-api-server/internal/scheduler" // Import the background jobs package
+	"go-api-server/internal/templates" // Import the built-in goal templates
 )
 
 func main() {
-    // Initialize the in-memory database
-    // This creates a new instance of our database to store users
-    // In production, you'd connect to a real database here (PostgreSQL, MySQL, MongoDB, etc.)
-    handler.DB = database.NewInMemoryDB()
+    // Load settings from environment variables (DB_DRIVER, DB_PATH, PORT)
+    cfg := config.Load()
+
+    // "go run cmd/main.go migrate <up|down|status>" manages the database schema
+    // instead of starting the server (see runMigrate)
+    if len(os.Args) > 1 && os.Args[1] == "migrate" {
+        if err := runMigrate(cfg, os.Args[2:]); err != nil {
+            log.Fatalf("migrate: %v", err)
+        }
+        return
+    }
+
+    // Open the storage backend selected in the configuration
+    // "memory" keeps data in Go maps, "sqlite" persists it to a file on disk
+    db, err := openStore(cfg)
+    if err != nil {
+        log.Fatalf("Failed to open %s database: %v", cfg.DBDriver, err)
+    }
+
+    // Add the built-in goal templates from templates/builtin.json to the
+    // store, updating the ones that changed since the last start.
+    // This fails if migrations are pending, which only costs the templates
+    if err := templates.Seed(db, time.Now()); err != nil {
+        log.Printf("WARNING: failed to seed goal templates: %v", err)
+    }
+
+    // Load the exchange-rate table used to convert totals between currencies
+    rates, err := loadRates(cfg.ExchangeRatesFile)
+    if err != nil {
+        log.Fatalf("Failed to load exchange rates: %v", err)
+    }
+
+    // Start the background job that rolls recurring goals over into their
+    // next period once the current one has ended
+    if cfg.RolloverInterval > 0 {
+        go scheduler.NewRollover(db, cfg.RolloverInterval).Run(context.Background())
+    }
+
+    // Start the background job that removes deleted goals for good once
+    // they can no longer be restored
+    if cfg.PurgeInterval > 0 {
+        go scheduler.NewPurge(db, cfg.GoalRetention, cfg.PurgeInterval).Run(context.Background())
+    }
+
+    // Inject the database into the handlers
+    // Any database.Store implementation can be passed here
+    h := handler.NewHandler(db, rates, cfg.AdminEmails)
 
     // Initialize the Gin router with all routes
     // This sets up all our API endpoints (/get, /post, /signup, /login, /logout)
-    r := router.SetupRouter()
+    r := router.SetupRouter(h)
 
-    // Start the HTTP server on port 8080
+    // Start the HTTP server on the configured port (default :8080)
     // This will block and keep the server running until interrupted
-    if err := r.Run(":8080"); err != nil {
+    if err := r.Run(cfg.Port); err != nil {
         // Log fatal error if the server fails to start
         // In production, use a proper logger instead of panic
         panic("Failed to start server: " + err.Error())
     }
-}
\ No newline at end of file
+}
+
+// openStore creates the database.Store for the configured driver.
+func openStore(cfg config.Config) (database.Store, error) {
+    switch cfg.DBDriver {
+    case config.DriverMemory:
+        // With DB_WAL_DIR set, the map-based store logs every change to disk
+        // and restores it on startup (snapshot + write-ahead log)
+        if cfg.WALDir != "" {
+            return database.OpenDurableInMemoryDB(cfg.WALDir, database.DurabilityOptions{
+                SnapshotEvery: cfg.SnapshotEvery,
+            })
+        }
+
+        // Initialize the in-memory database
+        // Data is lost when the server stops, which is handy for demos and tests
+        return database.NewInMemoryDB(), nil
+    case config.DriverSQLite:
+        // Open (or create) the SQLite file so data survives restarts
+        db, err := database.NewSQLiteDB(cfg.DBPath)
+        if err != nil {
+            return nil, err
+        }
+        if err := prepareSchema(db, cfg.DBAutoMigrate); err != nil {
+            db.Close()
+            return nil, err
+        }
+        return db, nil
+    default:
+        return nil, fmt.Errorf("unknown DB_DRIVER %q (expected %q or %q)", cfg.DBDriver, config.DriverMemory, config.DriverSQLite)
+    }
+}
+
+// loadRates creates the exchange-rate table and loads it from path.
+// A missing file only logs a warning: admins can create it through the API.
+func loadRates(path string) (*exchange.Table, error) {
+    rates := exchange.NewTable(path)
+    if path == "" {
+        return rates, nil
+    }
+
+    err := rates.Reload()
+    if errors.Is(err, os.ErrNotExist) {
+        log.Printf("WARNING: exchange-rate file %s not found; starting with no rates", path)
+        return rates, nil
+    }
+    return rates, err
+}
+
+// prepareSchema applies pending migrations when autoMigrate is enabled.
+// Otherwise it only warns, so an operator can run "migrate up" deliberately.
+func prepareSchema(db *database.SQLiteDB, autoMigrate bool) error {
+    migrator, err := db.Migrator()
+    if err != nil {
+        return err
+    }
+
+    if autoMigrate {
+        applied, err := migrator.Up()
+        if err != nil {
+            return err
+        }
+        for _, m := range applied {
+            log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+        }
+        return nil
+    }
+
+    pending, err := migrator.Pending()
+    if err != nil {
+        return err
+    }
+    if pending > 0 {
+        log.Printf("WARNING: %d pending migration(s); run \"migrate up\" or set DB_AUTO_MIGRATE=true", pending)
+    }
+    return nil
+}
+
+// runMigrate implements the "migrate" command:
+//
+//    go run cmd/main.go migrate up      apply all pending migrations
+//    go run cmd/main.go migrate down    revert the most recent migration
+//    go run cmd/main.go migrate status  list migrations and whether they ran
+//
+// Migrations only apply to the SQLite backend; the in-memory store has no schema.
+func runMigrate(cfg config.Config, args []string) error {
+    if len(args) != 1 {
+        return errors.New("usage: migrate <up|down|status>")
+    }
+    if cfg.DBDriver != config.DriverSQLite {
+        return fmt.Errorf("migrations require DB_DRIVER=%s (current: %q)", config.DriverSQLite, cfg.DBDriver)
+    }
+
+    db, err := database.NewSQLiteDB(cfg.DBPath)
+    if err != nil {
+        return err
+    }
+    defer db.Close()
+
+    migrator, err := db.Migrator()
+    if err != nil {
+        return err
+    }
+
+    switch args[0] {
+    case "up":
+        applied, err := migrator.Up()
+        for _, m := range applied {
+            fmt.Printf("applied  %04d_%s\n", m.Version, m.Name)
+        }
+        if err != nil {
+            return err
+        }
+        if len(applied) == 0 {
+            fmt.Println("database is up to date")
+        }
+    case "down":
+        reverted, err := migrator.Down()
+        if err != nil {
+            return err
+        }
+        if reverted == nil {
+            fmt.Println("no migrations to revert")
+            return nil
+        }
+        fmt.Printf("reverted %04d_%s\n", reverted.Version, reverted.Name)
+    case "status":
+        statuses, err := migrator.Status()
+        if err != nil {
+            return err
+        }
+        for _, s := range statuses {
+            state := "pending"
+            if s.Applied {
+                state = "applied " + s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+            }
+            fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, state)
+        }
+    default:
+        return fmt.Errorf("unknown migrate command %q (expected up, down or status)", args[0])
+    }
+    return nil
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported values for Config.DBDriver.
+const (
+	// DriverMemory keeps everything in Go maps (data is lost on restart)
+	DriverMemory = "memory"
+
+	// DriverSQLite stores data in an embedded SQLite database file
+	DriverSQLite = "sqlite"
+)
+
+// Config holds the settings the server reads at startup.
+// Every field can be overridden with an environment variable so the same
+// binary can run locally (in-memory) or in a deployment (SQLite on disk).
+type Config struct {
+	// Port is the address the HTTP server listens on, e.g. ":8080"
+	// Env: PORT
+	Port string
+
+	// DBDriver selects the storage backend: "memory" or "sqlite"
+	// Env: DB_DRIVER
+	DBDriver string
+
+	// DBPath is the SQLite database file (only used when DBDriver is "sqlite")
+	// Env: DB_PATH
+	DBPath string
+
+	// DBAutoMigrate applies pending schema migrations when the server starts.
+	// It is off by default so schema changes stay a deliberate step
+	// ("go run cmd/main.go migrate up").
+	// Env: DB_AUTO_MIGRATE
+	DBAutoMigrate bool
+
+	// WALDir enables durability for the in-memory store: mutations are
+	// written to a write-ahead log in this directory and replayed on startup.
+	// Empty (the default) keeps the in-memory store purely in memory.
+	// Env: DB_WAL_DIR
+	WALDir string
+
+	// SnapshotEvery is how many WAL records are written before the log is
+	// compacted into a snapshot (only used when WALDir is set)
+	// Env: DB_SNAPSHOT_EVERY
+	SnapshotEvery int
+
+	// ExchangeRatesFile is a CSV or JSON file with the exchange-rate table.
+	// It is loaded at startup and rewritten when an admin replaces the rates.
+	// Empty means rates can only be set through the admin API (not saved).
+	// Env: EXCHANGE_RATES_FILE
+	ExchangeRatesFile string
+
+	// AdminEmails lists the users allowed to call the /admin endpoints
+	// Env: ADMIN_EMAILS (comma-separated)
+	AdminEmails []string
+
+	// RolloverInterval is how often the background job checks for recurring
+	// goals whose period has ended (a Go duration such as "1m"; 0 disables it)
+	// Env: ROLLOVER_INTERVAL
+	RolloverInterval time.Duration
+
+	// GoalRetention is how long a deleted goal can still be restored before
+	// the purge job removes it for good (a Go duration such as "720h")
+	// Env: GOAL_RETENTION
+	GoalRetention time.Duration
+
+	// PurgeInterval is how often the purge job looks for deleted goals past
+	// GoalRetention (0 disables it, keeping deleted goals forever)
+	// Env: PURGE_INTERVAL
+	PurgeInterval time.Duration
+}
+
+// Load reads the configuration from environment variables,
+// falling back to sensible defaults for local development.
+func Load() Config {
+	return Config{
+		Port:     getEnv("PORT", ":8080"),
+		DBDriver: getEnv("DB_DRIVER", DriverMemory),
+		DBPath:   getEnv("DB_PATH", "data.db"),
+
+		DBAutoMigrate: getEnvBool("DB_AUTO_MIGRATE", false),
+		WALDir:        getEnv("DB_WAL_DIR", ""),
+		SnapshotEvery: getEnvInt("DB_SNAPSHOT_EVERY", 1000),
+
+		ExchangeRatesFile: getEnv("EXCHANGE_RATES_FILE", ""),
+		AdminEmails:       getEnvList("ADMIN_EMAILS"),
+
+		RolloverInterval: getEnvDuration("ROLLOVER_INTERVAL", time.Minute),
+		GoalRetention:    getEnvDuration("GOAL_RETENTION", 30*24*time.Hour),
+		PurgeInterval:    getEnvDuration("PURGE_INTERVAL", time.Hour),
+	}
+}
+
+// getEnv returns the value of the environment variable key,
+// or fallback if the variable is unset or empty.
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvBool parses the environment variable key as a boolean
+// ("1", "true", "false", ...), falling back if it is unset or invalid.
+func getEnvBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvInt parses the environment variable key as an integer,
+// falling back if it is unset or invalid.
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvDuration parses the environment variable key as a Go duration
+// ("30s", "5m"), falling back if it is unset or invalid.
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvList splits the environment variable key on commas,
+// dropping surrounding spaces and empty entries.
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"go-api-server/internal/models"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor is malformed or was
+// issued for a different sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// goalCursor marks where a page of goals ended: the sort key and ID of its
+// last goal. Listing resumes strictly after that (key, id) pair, so pages stay
+// stable even when goals are added or removed in between.
+// It is sent to clients as opaque URL-safe base64 JSON.
+type goalCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Key   string `json:"k"`
+	ID    string `json:"id"`
+}
+
+// goalSortKey returns the value goals are ordered by for the given sort field,
+// as a string that orders the same way the SQL columns do: fixed-width
+// timestamps for dates, and a zero-padded integer for progress.
+func goalSortKey(goal *models.Goal, sort string) string {
+	switch sort {
+	case models.GoalSortEndDate:
+		return formatTime(goal.EndDate)
+	case models.GoalSortProgress:
+		return formatProgress(goal.ProgressBasisPoints())
+	default:
+		return formatTime(goal.CreatedAt)
+	}
+}
+
+// formatProgress zero-pads basis points so they compare correctly as strings.
+func formatProgress(bp int64) string {
+	if bp < 0 {
+		bp = 0
+	}
+	s := strconv.FormatInt(bp, 10)
+	for len(s) < 19 {
+		s = "0" + s
+	}
+	return s
+}
+
+// encodeGoalCursor builds the cursor pointing after goal.
+func encodeGoalCursor(query models.GoalQuery, goal *models.Goal) string {
+	data, _ := json.Marshal(goalCursor{
+		Sort:  query.Sort,
+		Order: query.Order,
+		Key:   goalSortKey(goal, query.Sort),
+		ID:    goal.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeGoalCursor parses query.Cursor. It returns nil for the first page and
+// ErrInvalidCursor if the cursor can't be read or doesn't match the query's sort.
+func decodeGoalCursor(query models.GoalQuery) (*goalCursor, error) {
+	if query.Cursor == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(query.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor goalCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != query.Sort || cursor.Order != query.Order {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort == models.GoalSortProgress {
+		_, err = strconv.ParseInt(cursor.Key, 10, 64)
+	} else {
+		_, err = parseTime(cursor.Key)
+	}
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// normalizeGoalQuery fills in the default sort, order and page size.
+func normalizeGoalQuery(query models.GoalQuery) models.GoalQuery {
+	if query.Sort == "" {
+		query.Sort = models.GoalSortCreatedAt
+	}
+	if query.Order == "" {
+		query.Order = "asc"
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultGoalPageLimit
+	}
+	return query
+}
+
+// defaultGoalPageLimit is the page size when a query doesn't set one.
+const defaultGoalPageLimit = 20
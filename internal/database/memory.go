@@ -1,26 +1,74 @@
 package database
 
 import (
-	"errors"
+	"encoding/json"
 	"go-api-server/internal/models"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 // InMemoryDB represents an in-memory database for storing users.
 // This is a simple implementation using Go maps for learning purposes.
 // In production, you'd use a real database like PostgreSQL, MySQL, or MongoDB.
+//
+// The database never shares pointers with its callers: values are copied on
+// the way in and on the way out. Otherwise a handler could modify a stored
+// goal without holding the lock, which is a data race. To change a goal
+// safely, use UpdateGoalFunc, which does the read-modify-write under the lock.
 type InMemoryDB struct {
 	// users stores all users with email as the key for quick lookups
 	// map[email]User allows us to quickly check if an email already exists
+	// The key is normalized (see models.NormalizeEmail), so lookups ignore case
 	users map[string]*models.User
 
+	// usersByID is a secondary index over users: map[userID]User
+	// It points at the same User values as users, so lookups by ID are O(1)
+	// instead of scanning every user
+	usersByID map[string]*models.User
+
 	// goals stores all goals with ID as the key
 	goals map[string]*models.Goal
-	
+
+	// goalsByUser is a secondary index: map[userID]set of goal IDs
+	// GetGoalsByUserID only visits that user's goals (O(k) for k goals)
+	// instead of scanning every goal in the database
+	goalsByUser map[string]map[string]struct{}
+
+	// contributions is each goal's ledger: map[goalID]contributions, oldest first
+	// Entries are only ever appended; they are dropped when the goal is deleted
+	contributions map[string][]*models.Contribution
+
+	// periods holds the archived periods of recurring goals:
+	// map[goalID]periods, oldest first; dropped when the goal is deleted
+	periods map[string][]*models.GoalPeriod
+
+	// categories holds every user's goal categories: map[categoryID]category
+	// Users have a handful of categories, so lookups by user just scan them
+	categories map[string]*models.Category
+
+	// members holds who each goal is shared with: map[goalID]map[userID]member
+	// memberGoals is the reverse index, map[userID]set of goal IDs, so
+	// ListGoals finds shared goals without scanning every goal
+	members     map[string]map[string]*models.GoalMember
+	memberGoals map[string]map[string]struct{}
+
+	// invitations holds every invitation: map[invitationID]invitation
+	invitations map[string]*models.Invitation
+
+	// templates holds the built-in goal templates and the users' private
+	// ones: map[templateID]template
+	templates map[string]*models.GoalTemplate
+
 	// mu is a read-write mutex to protect concurrent access to the users map
 	// This prevents race conditions when multiple goroutines access the database
 	// RWMutex allows multiple readers or one writer at a time
 	mu sync.RWMutex
+
+	// wal persists every mutation when durability is enabled
+	// (see OpenDurableInMemoryDB in wal.go); nil means purely in-memory
+	wal *walLog
 }
 
 // NewInMemoryDB creates and initializes a new in-memory database instance.
@@ -28,8 +76,161 @@ type InMemoryDB struct {
 func NewInMemoryDB() *InMemoryDB {
 	return &InMemoryDB{
 		// Initialize the users map with make()
-		users: make(map[string]*models.User),
-		goals: make(map[string]*models.Goal),
+		users:         make(map[string]*models.User),
+		usersByID:     make(map[string]*models.User),
+		goals:         make(map[string]*models.Goal),
+		goalsByUser:   make(map[string]map[string]struct{}),
+		contributions: make(map[string][]*models.Contribution),
+		periods:       make(map[string][]*models.GoalPeriod),
+		categories:    make(map[string]*models.Category),
+		members:       make(map[string]map[string]*models.GoalMember),
+		memberGoals:   make(map[string]map[string]struct{}),
+		invitations:   make(map[string]*models.Invitation),
+		templates:     make(map[string]*models.GoalTemplate),
+	}
+}
+
+// The helpers below are the only code that writes to the maps, so the
+// secondary indexes can never drift from the primary maps.
+// They must be called with db.mu held for writing.
+
+// putUser stores user in the primary map and the ID index.
+func (db *InMemoryDB) putUser(user *models.User) {
+	db.users[models.NormalizeEmail(user.Email)] = user
+	db.usersByID[user.ID] = user
+}
+
+// removeUser deletes the user with the given email from both maps.
+func (db *InMemoryDB) removeUser(email string) {
+	email = models.NormalizeEmail(email)
+	if user, exists := db.users[email]; exists {
+		delete(db.usersByID, user.ID)
+		delete(db.users, email)
+	}
+}
+
+// putGoal stores (or replaces) goal and updates the per-user index.
+// If an update moved the goal to another user, it is unlinked from the old one.
+// Goals logged before goals had a status are active.
+func (db *InMemoryDB) putGoal(goal *models.Goal) {
+	if goal.Status == "" {
+		goal.Status = models.GoalActive
+	}
+	if old, exists := db.goals[goal.ID]; exists && old.UserID != goal.UserID {
+		db.unindexGoal(old)
+	}
+	db.goals[goal.ID] = goal
+
+	ids, exists := db.goalsByUser[goal.UserID]
+	if !exists {
+		ids = make(map[string]struct{})
+		db.goalsByUser[goal.UserID] = ids
+	}
+	ids[goal.ID] = struct{}{}
+}
+
+// removeGoal deletes the goal with the given ID, its index entry, its ledger,
+// its archived periods, its members and its invitations.
+func (db *InMemoryDB) removeGoal(id string) {
+	if goal, exists := db.goals[id]; exists {
+		db.unindexGoal(goal)
+		delete(db.goals, id)
+		delete(db.contributions, id)
+		delete(db.periods, id)
+		for userID := range db.members[id] {
+			db.removeMember(id, userID)
+		}
+		for invitationID, invitation := range db.invitations {
+			if invitation.GoalID == id {
+				delete(db.invitations, invitationID)
+			}
+		}
+	}
+}
+
+// putMember stores (or replaces) member and updates the reverse index.
+func (db *InMemoryDB) putMember(member *models.GoalMember) {
+	members, exists := db.members[member.GoalID]
+	if !exists {
+		members = make(map[string]*models.GoalMember)
+		db.members[member.GoalID] = members
+	}
+	members[member.UserID] = member
+
+	goals, exists := db.memberGoals[member.UserID]
+	if !exists {
+		goals = make(map[string]struct{})
+		db.memberGoals[member.UserID] = goals
+	}
+	goals[member.GoalID] = struct{}{}
+}
+
+// removeMember deletes the membership of userID in goalID from both maps,
+// dropping emptied sets so the indexes don't leak.
+func (db *InMemoryDB) removeMember(goalID, userID string) {
+	members := db.members[goalID]
+	delete(members, userID)
+	if len(members) == 0 {
+		delete(db.members, goalID)
+	}
+
+	goals := db.memberGoals[userID]
+	delete(goals, goalID)
+	if len(goals) == 0 {
+		delete(db.memberGoals, userID)
+	}
+}
+
+// putInvitation stores (or replaces) invitation.
+func (db *InMemoryDB) putInvitation(invitation *models.Invitation) {
+	db.invitations[invitation.ID] = invitation
+}
+
+// putTemplate stores (or replaces) template.
+func (db *InMemoryDB) putTemplate(template *models.GoalTemplate) {
+	db.templates[template.ID] = template
+}
+
+// appendContribution adds contribution to the end of its goal's ledger.
+func (db *InMemoryDB) appendContribution(contribution *models.Contribution) {
+	db.contributions[contribution.GoalID] = append(db.contributions[contribution.GoalID], contribution)
+}
+
+// appendPeriod adds period to the end of its goal's archive.
+func (db *InMemoryDB) appendPeriod(period *models.GoalPeriod) {
+	db.periods[period.GoalID] = append(db.periods[period.GoalID], period)
+}
+
+// putCategory stores (or replaces) category.
+func (db *InMemoryDB) putCategory(category *models.Category) {
+	db.categories[category.ID] = category
+}
+
+// removeCategory deletes the category with the given ID and takes its goals
+// out of it, giving each of them the next version.
+func (db *InMemoryDB) removeCategory(id string) {
+	category, exists := db.categories[id]
+	if !exists {
+		return
+	}
+	for goalID := range db.goalsByUser[category.UserID] {
+		if stored := db.goals[goalID]; stored.CategoryID == id {
+			goal := stored.Clone()
+			goal.CategoryID = ""
+			goal.Version++
+			db.putGoal(goal)
+		}
+	}
+	delete(db.categories, id)
+}
+
+// unindexGoal removes goal from its owner's goal set,
+// dropping the set entirely once it is empty so the index doesn't leak.
+func (db *InMemoryDB) unindexGoal(goal *models.Goal) {
+	ids := db.goalsByUser[goal.UserID]
+	delete(ids, goal.ID)
+	if len(ids) == 0 {
+		delete(db.goalsByUser, goal.UserID)
 	}
 }
 
@@ -47,14 +248,22 @@ func (db *InMemoryDB) CreateUser(user *models.User) error {
 	// defer means "run this when the function exits"
 	defer db.mu.Unlock()
 	
-	// Check if a user with this email already exists
-	if _, exists := db.users[user.Email]; exists {
+	// Check if a user with this email already exists (in any case)
+	if _, exists := db.users[models.NormalizeEmail(user.Email)]; exists {
 		// Return an error if the email is already registered
-		return errors.New("user with this email already exists")
+		return ErrUserExists
+	}
+	
+	// Write the change to the WAL first (no-op when durability is disabled)
+	// If logging fails we don't apply it, so memory and disk never disagree
+	persisted := toPersistedUser(user)
+	if err := db.logMutation(walRecord{Op: opCreateUser, User: &persisted}); err != nil {
+		return err
 	}
 	
-	// Store the user in the map with email as the key
-	db.users[user.Email] = user
+	// Store a copy of the user in the map with email as the key (and index it by ID)
+	db.putUser(user.Clone())
+	db.maybeSnapshot()
 	
 	// Return nil to indicate success (no error)
 	return nil
@@ -73,21 +282,21 @@ func (db *InMemoryDB) GetUserByEmail(email string) (*models.User, error) {
 	// Unlock when the function exits
 	defer db.mu.RUnlock()
 	
-	// Look up the user by email
+	// Look up the user by email, normalized like the map's keys
 	// The comma-ok idiom: user gets the value, exists is true/false
-	user, exists := db.users[email]
+	user, exists := db.users[models.NormalizeEmail(email)]
 	if !exists {
 		// Return nil user and an error if not found
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 	
-	// Return the found user and no error
-	return user, nil
+	// Return a copy of the found user and no error
+	return user.Clone(), nil
 }
 
 // GetUserByID retrieves a user from the database by their ID.
-// Note: This is less efficient than GetUserByEmail because we have to iterate
-// through all users. In a real database, you'd have an index on the ID field.
+// This uses the usersByID index, just like a real database would use an index
+// on the ID column, so it is as fast as GetUserByEmail.
 // Parameters:
 //   - id: the user ID to search for
 // Returns:
@@ -98,17 +307,14 @@ func (db *InMemoryDB) GetUserByID(id string) (*models.User, error) {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 	
-	// Iterate through all users to find matching ID
-	// range iterates over map: key (email), value (user pointer)
-	for _, user := range db.users {
-		if user.ID == id {
-			// Found the user, return it
-			return user, nil
-		}
+	// Look up the user in the ID index
+	user, exists := db.usersByID[id]
+	if !exists {
+		// No user found with this ID
+		return nil, ErrUserNotFound
 	}
 	
-	// No user found with this ID
-	return nil, errors.New("user not found")
+	return user.Clone(), nil
 }
 
 // DeleteUser removes a user from the database by their email.
@@ -124,21 +330,96 @@ func (db *InMemoryDB) DeleteUser(email string) error {
 	defer db.mu.Unlock()
 	
 	// Check if user exists before trying to delete
-	if _, exists := db.users[email]; !exists {
-		return errors.New("user not found")
+	if _, exists := db.users[models.NormalizeEmail(email)]; !exists {
+		return ErrUserNotFound
+	}
+	
+	if err := db.logMutation(walRecord{Op: opDeleteUser, Email: email}); err != nil {
+		return err
+	}
+	
+	// Delete the user from the map and the ID index
+	db.removeUser(email)
+	db.maybeSnapshot()
+	
+	return nil
+}
+
+// UpdateUser saves changes to an existing user's settings.
+// The user is found by ID. The email is the login identity and is never
+// changed here: the stored email is kept whatever user.Email says.
+// Parameters:
+//   - user: pointer to the User struct with updated information
+// Returns:
+//   - error: nil if successful, ErrUserNotFound if no user has that ID
+func (db *InMemoryDB) UpdateUser(user *models.User) error {
+	// Lock for writing (exclusive access)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	
+	stored, exists := db.usersByID[user.ID]
+	if !exists {
+		return ErrUserNotFound
 	}
 	
-	// Delete the user from the map using the built-in delete function
-	delete(db.users, email)
+	updated := user.Clone()
+	updated.Email = stored.Email
+	updated.Streaks = stored.Streaks
+	
+	persisted := toPersistedUser(updated)
+	if err := db.logMutation(walRecord{Op: opUpdateUser, User: &persisted}); err != nil {
+		return err
+	}
+	
+	// Same email, so putUser replaces the entry in both maps
+	db.putUser(updated)
+	db.maybeSnapshot()
 	
 	return nil
 }
 
+// UpdateUserStreaks atomically updates the streaks of the user with the given ID.
+// The whole read-modify-write happens under the write lock, so deposits
+// recorded at the same time can't overwrite each other's streaks.
+// Parameters:
+//   - id: the ID of the user
+//   - fn: changes the streaks; returning an error aborts the update
+// Returns:
+//   - *models.Streaks: the saved streaks
+//   - error: ErrUserNotFound, or the error returned by fn
+func (db *InMemoryDB) UpdateUserStreaks(id string, fn func(streaks *models.Streaks) error) (*models.Streaks, error) {
+	// Lock for writing for the whole read-modify-write
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	
+	stored, exists := db.usersByID[id]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	
+	updated := stored.Clone()
+	if err := fn(&updated.Streaks); err != nil {
+		return nil, err
+	}
+	
+	persisted := toPersistedUser(updated)
+	if err := db.logMutation(walRecord{Op: opUpdateUser, User: &persisted}); err != nil {
+		return nil, err
+	}
+	
+	db.putUser(updated)
+	db.maybeSnapshot()
+	
+	streaks := updated.Streaks
+	return &streaks, nil
+}
+
 // GetAllUsers returns a slice of all users in the database.
 // This is useful for admin functionality or testing.
 // Returns:
 //   - []*models.User: slice containing pointers to all users
-func (db *InMemoryDB) GetAllUsers() []*models.User {
+//   - error: always nil for the in-memory store (required by the Store interface)
+func (db *InMemoryDB) GetAllUsers() ([]*models.User, error) {
 	// Lock for reading
 	db.mu.RLock()
 	defer db.mu.RUnlock()
@@ -149,10 +430,10 @@ func (db *InMemoryDB) GetAllUsers() []*models.User {
 	
 	// Iterate through the map and append each user to the slice
 	for _, user := range db.users {
-		users = append(users, user)
+		users = append(users, user.Clone())
 	}
 	
-	return users
+	return users, nil
 }
 
 // CreateGoal adds a new goal to the database.
@@ -172,11 +453,22 @@ func (db *InMemoryDB) CreateGoal(goal *models.Goal) error {
 	// Check if a goal with this ID already exists
 	if _, exists := db.goals[goal.ID]; exists {
 		// Return an error if the ID is already registered
-		return errors.New("goal with this ID already exists")
+		return ErrGoalExists
 	}
 
-	// Store the goal in the map with ID as the key
-	db.goals[goal.ID] = goal
+	// Every goal starts at version 1; each update increments it
+	goal.Version = 1
+	if goal.Status == "" {
+		goal.Status = models.GoalActive
+	}
+
+	if err := db.logMutation(walRecord{Op: opCreateGoal, Goal: goal}); err != nil {
+		return err
+	}
+
+	// Store a copy of the goal in the map with ID as the key (and index it by user)
+	db.putGoal(goal.Clone())
+	db.maybeSnapshot()
 
 	// Return nil to indicate success (no error)
 	return nil
@@ -197,18 +489,84 @@ func (db *InMemoryDB) GetGoalsByUserID(userID string) ([]*models.Goal, error) {
 
 	// Create a slice to hold the user's goals
 	var userGoals []*models.Goal
-	// Iterate through all goals to find those belonging to the user
-	for _, goal := range db.goals {
-		if goal.UserID == userID {
-			// Add the goal to the user's goals slice
-			userGoals = append(userGoals, goal)
-		}
+	// Only visit the goal IDs indexed under this user
+	for id := range db.goalsByUser[userID] {
+		// Add the goal to the user's goals slice
+		userGoals = append(userGoals, db.goals[id].Clone())
 	}
 
 	// Return the slice of user goals (empty if none found) and no error
 	return userGoals, nil
 }
 
+// ListGoals returns one page of a user's goals matching query.
+// The user's goals are filtered and sorted in memory on every call, which is
+// fine for the handful of goals a user has.
+func (db *InMemoryDB) ListGoals(query models.GoalQuery) (*models.GoalPage, error) {
+	query = normalizeGoalQuery(query)
+	cursor, err := decodeGoalCursor(query)
+	if err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	goals := []*models.Goal{}
+	// A goal the user owns and is also a member of is in both indexes;
+	// seen keeps it from being listed (and paged) twice
+	seen := make(map[string]struct{})
+	for _, ids := range []map[string]struct{}{db.goalsByUser[query.UserID], db.memberGoals[query.UserID]} {
+		for id := range ids {
+			if _, dup := seen[id]; dup {
+				continue
+			}
+			seen[id] = struct{}{}
+			if goal := db.goals[id]; query.Matches(goal) {
+				goals = append(goals, goal.Clone())
+			}
+		}
+	}
+	db.mu.RUnlock()
+
+	// Order by (sort key, ID); the ID makes the order total, so a cursor
+	// identifies exactly one position
+	keys := make(map[string]string, len(goals))
+	for _, goal := range goals {
+		keys[goal.ID] = goalSortKey(goal, query.Sort)
+	}
+	less := func(keyA, idA, keyB, idB string) bool {
+		if keyA != keyB {
+			return keyA < keyB
+		}
+		return idA < idB
+	}
+	sort.Slice(goals, func(i, j int) bool {
+		a, b := goals[i], goals[j]
+		if query.Descending() {
+			a, b = b, a
+		}
+		return less(keys[a.ID], a.ID, keys[b.ID], b.ID)
+	})
+
+	// Skip everything up to and including the cursor position
+	start := 0
+	if cursor != nil {
+		start = sort.Search(len(goals), func(i int) bool {
+			key, id := keys[goals[i].ID], goals[i].ID
+			if query.Descending() {
+				return less(key, id, cursor.Key, cursor.ID)
+			}
+			return less(cursor.Key, cursor.ID, key, id)
+		})
+	}
+
+	page := &models.GoalPage{Goals: goals[start:]}
+	if len(page.Goals) > query.Limit {
+		page.Goals = page.Goals[:query.Limit]
+		page.NextCursor = encodeGoalCursor(query, page.Goals[query.Limit-1])
+	}
+	return page, nil
+}
+
 // GetGoalByID retrieves a goal from the database by its ID.
 // Parameters:
 //   - id: the ID of the goal to retrieve
@@ -226,34 +584,94 @@ func (db *InMemoryDB) GetGoalByID(id string) (*models.Goal, error) {
 	goal, exists := db.goals[id]
 	if !exists {
 		// Return nil goal and an error if not found
-		return nil, errors.New("goal not found")
+		return nil, ErrGoalNotFound
 	}
 
-	// Return the found goal and no error
-	return goal, nil
+	// Return a copy of the found goal and no error
+	return goal.Clone(), nil
 }
 
 // UpdateGoal updates an existing goal in the database.
+// This is a compare-and-swap on the goal's version: the update only succeeds
+// if nobody else saved the goal since the caller read it. On success the
+// version is incremented (and goal.Version is set to the new value).
 // Parameters:
 //   - goal: pointer to the Goal struct with updated information
 // Returns:
-//   - error: nil if successful, error if goal doesn't exist
+//   - error: nil if successful, ErrGoalNotFound if the goal doesn't exist,
+//     ErrVersionConflict if goal.Version is stale
 func (db *InMemoryDB) UpdateGoal(goal *models.Goal) error {
 	// Lock the database for writing (exclusive access)
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
 	// Check if the goal exists before trying to update
-	if _, exists := db.goals[goal.ID]; !exists {
-		return errors.New("goal not found")
+	stored, exists := db.goals[goal.ID]
+	if !exists {
+		return ErrGoalNotFound
 	}
 
-	// Update the goal in the map
-	db.goals[goal.ID] = goal
+	// Reject the update if the goal changed since the caller read it
+	if stored.Version != goal.Version {
+		return ErrVersionConflict
+	}
 
+	updated := goal.Clone()
+	updated.Version++
+
+	if err := db.logMutation(walRecord{Op: opUpdateGoal, Goal: updated}); err != nil {
+		return err
+	}
+
+	// Update the goal in the map (and the per-user index if the owner changed)
+	db.putGoal(updated)
+	db.maybeSnapshot()
+
+	// Let the caller know the new version
+	goal.Version = updated.Version
 	return nil
 }
 
+// UpdateGoalFunc atomically reads, modifies and saves a goal.
+// The whole read-modify-write happens under the write lock, so two concurrent
+// deposits to the same goal can't both read the old amount and lose one increment.
+// Parameters:
+//   - id: the ID of the goal to update
+//   - fn: receives a copy of the current goal and modifies it in place;
+//     if fn returns an error, nothing is saved and that error is returned
+// Returns:
+//   - *models.Goal: a copy of the goal as saved
+//   - error: nil if successful, ErrGoalNotFound, or the error returned by fn
+func (db *InMemoryDB) UpdateGoalFunc(id string, fn func(goal *models.Goal) error) (*models.Goal, error) {
+	// Lock for writing for the whole read-modify-write
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, exists := db.goals[id]
+	if !exists {
+		return nil, ErrGoalNotFound
+	}
+
+	// Let fn work on a copy so a failed update leaves the stored goal untouched
+	goal := stored.Clone()
+	if err := fn(goal); err != nil {
+		return nil, err
+	}
+	// fn must not be able to move the goal to a different key or rewrite
+	// its version; the version always advances by exactly one per update
+	goal.ID = id
+	goal.Version = stored.Version + 1
+
+	if err := db.logMutation(walRecord{Op: opUpdateGoal, Goal: goal}); err != nil {
+		return nil, err
+	}
+
+	db.putGoal(goal)
+	db.maybeSnapshot()
+
+	return goal.Clone(), nil
+}
+
 // DeleteGoal removes a goal from the database by its ID.
 // Parameters:
 //   - id: the ID of the goal to delete
@@ -266,11 +684,792 @@ func (db *InMemoryDB) DeleteGoal(id string) error {
 
 	// Check if the goal exists before trying to delete
 	if _, exists := db.goals[id]; !exists {
-		return errors.New("goal not found")
+		return ErrGoalNotFound
+	}
+
+	if err := db.logMutation(walRecord{Op: opDeleteGoal, ID: id}); err != nil {
+		return err
+	}
+
+	// Delete the goal from the map and the per-user index
+	db.removeGoal(id)
+	db.maybeSnapshot()
+
+	return nil
+}
+
+// PurgeDeletedGoals removes the goals deleted before deletedBefore for good.
+// Each goal is logged as its own delete, the same as DeleteGoal.
+// Parameters:
+//   - deletedBefore: goals deleted before this time are removed
+// Returns:
+//   - int: how many goals were removed
+//   - error: nil if successful, or the error writing the WAL
+func (db *InMemoryDB) PurgeDeletedGoals(deletedBefore time.Time) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	purged := 0
+	for id, goal := range db.goals {
+		if !goal.Deleted() || !goal.DeletedAt.Before(deletedBefore) {
+			continue
+		}
+		if err := db.logMutation(walRecord{Op: opDeleteGoal, ID: id}); err != nil {
+			return purged, err
+		}
+		db.removeGoal(id)
+		purged++
+	}
+	db.maybeSnapshot()
+
+	return purged, nil
+}
+
+// AddContribution records a contribution and applies it to its goal in one
+// step: the ledger entry and the new CurrentAmount are saved together under
+// the lock (and in a single WAL record), so they can never disagree.
+// Parameters:
+//   - contribution: the ledger entry; contribution.GoalID selects the goal
+//   - check: runs on a copy of the goal first; returning an error aborts
+// Returns:
+//   - *models.Goal: a copy of the updated goal
+//   - error: ErrGoalNotFound if the goal doesn't exist, or check's error
+func (db *InMemoryDB) AddContribution(contribution *models.Contribution, check func(goal *models.Goal) error) (*models.Goal, error) {
+	// Lock for writing for the whole read-modify-write
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, exists := db.goals[contribution.GoalID]
+	if !exists {
+		return nil, ErrGoalNotFound
+	}
+
+	goal := stored.Clone()
+	if err := check(goal); err != nil {
+		return nil, err
+	}
+	goal.ID = stored.ID
+	if err := goal.AddProgress(contribution.Amount, contribution.CreatedAt); err != nil {
+		return nil, err
+	}
+	goal.Version = stored.Version + 1
+
+	entry := contribution.Clone()
+	if err := db.logMutation(walRecord{Op: opAddContribution, Goal: goal, Contribution: entry}); err != nil {
+		return nil, err
+	}
+
+	db.putGoal(goal)
+	db.appendContribution(entry)
+	db.maybeSnapshot()
+
+	return goal.Clone(), nil
+}
+
+// AddContributions records contributions to several goals in one atomic step,
+// written to the WAL as a single record so a crash can't leave it half done.
+// Parameters:
+//   - contributions: one ledger entry per goal (each goal at most once)
+//   - check: inspects copies of the goals and may fill in the amounts
+// Returns:
+//   - []*models.Goal: the goals in the order of contributions
+//   - error: ErrGoalNotFound if a goal doesn't exist, or check's error
+func (db *InMemoryDB) AddContributions(contributions []*models.Contribution, check func(goals []*models.Goal) error) ([]*models.Goal, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	goals := make([]*models.Goal, len(contributions))
+	for i, contribution := range contributions {
+		stored, exists := db.goals[contribution.GoalID]
+		if !exists {
+			return nil, ErrGoalNotFound
+		}
+		goals[i] = stored.Clone()
+	}
+	if err := check(goals); err != nil {
+		return nil, err
+	}
+
+	var changed []*models.Goal
+	var entries []*models.Contribution
+	for i, contribution := range contributions {
+		stored := db.goals[contribution.GoalID]
+		if contribution.Amount.IsZero() {
+			goals[i] = stored.Clone()
+			continue
+		}
+
+		goal := goals[i]
+		goal.ID = stored.ID
+		if err := goal.AddProgress(contribution.Amount, contribution.CreatedAt); err != nil {
+			return nil, err
+		}
+		goal.Version = stored.Version + 1
+		changed = append(changed, goal)
+		entries = append(entries, contribution.Clone())
+	}
+	if len(entries) == 0 {
+		return goals, nil
+	}
+
+	if err := db.logMutation(walRecord{Op: opAddContributions, Goals: changed, Contributions: entries}); err != nil {
+		return nil, err
+	}
+
+	for i := range changed {
+		db.putGoal(changed[i])
+		db.appendContribution(entries[i])
+	}
+	db.maybeSnapshot()
+
+	saved := make([]*models.Goal, len(goals))
+	for i, goal := range goals {
+		saved[i] = goal.Clone()
+	}
+	return saved, nil
+}
+
+// ListContributions returns a page of a goal's ledger, newest first.
+// Parameters:
+//   - goalID: the goal whose contributions to list
+//   - limit: maximum number of contributions to return
+//   - offset: number of (newest) contributions to skip
+// Returns:
+//   - []*models.Contribution: copies of the contributions on this page
+//   - int: total number of contributions for the goal
+//   - error: always nil for the in-memory store
+func (db *InMemoryDB) ListContributions(goalID string, limit, offset int) ([]*models.Contribution, int, error) {
+	// Lock for reading (allows concurrent reads)
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	ledger := db.contributions[goalID]
+	total := len(ledger)
+
+	// The ledger is stored oldest first, so walk it backwards
+	page := []*models.Contribution{}
+	for i := total - 1 - offset; i >= 0 && len(page) < limit; i-- {
+		page = append(page, ledger[i].Clone())
+	}
+	return page, total, nil
+}
+
+// ListRolloverDue returns copies of the active recurring goals whose period ended by now.
+// It scans every goal, which is fine for a background job running once a minute.
+func (db *InMemoryDB) ListRolloverDue(now time.Time) ([]*models.Goal, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var due []*models.Goal
+	for _, goal := range db.goals {
+		if goal.RolloverDue(now) {
+			due = append(due, goal.Clone())
+		}
+	}
+	return due, nil
+}
+
+// RollOverGoal archives the ended periods of a recurring goal and starts the
+// next one, all under the write lock so no progress update can slip in between.
+// Parameters:
+//   - id: the goal to roll over
+//   - now: the current time; periods that ended by now are archived
+//   - loc: the owner's time zone, whose calendar the new periods follow
+// Returns:
+//   - *models.Goal: a copy of the goal after the rollover
+//   - []*models.GoalPeriod: copies of the archived periods (empty if not due)
+//   - error: ErrGoalNotFound if the goal doesn't exist
+func (db *InMemoryDB) RollOverGoal(id string, now time.Time, loc *time.Location) (*models.Goal, []*models.GoalPeriod, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, exists := db.goals[id]
+	if !exists {
+		return nil, nil, ErrGoalNotFound
 	}
 
-	// Delete the goal from the map using the built-in delete function
-	delete(db.goals, id)
+	goal := stored.Clone()
+	periods := goal.RollOver(now, loc)
+	if len(periods) == 0 {
+		return stored.Clone(), nil, nil
+	}
+	goal.Version = stored.Version + 1
+
+	if err := db.logMutation(walRecord{Op: opRollOverGoal, Goal: goal, Periods: periods}); err != nil {
+		return nil, nil, err
+	}
+
+	db.putGoal(goal)
+	copies := make([]*models.GoalPeriod, len(periods))
+	for i, period := range periods {
+		db.appendPeriod(period)
+		copies[i] = period.Clone()
+	}
+	db.maybeSnapshot()
+
+	return goal.Clone(), copies, nil
+}
+
+// ListGoalPeriods returns a page of a goal's archived periods, newest first.
+// Parameters:
+//   - goalID: the goal whose periods to list
+//   - limit: maximum number of periods to return
+//   - offset: number of (newest) periods to skip
+// Returns:
+//   - []*models.GoalPeriod: copies of the periods on this page
+//   - int: total number of archived periods for the goal
+//   - error: always nil for the in-memory store
+func (db *InMemoryDB) ListGoalPeriods(goalID string, limit, offset int) ([]*models.GoalPeriod, int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	archive := db.periods[goalID]
+	total := len(archive)
+
+	// The archive is stored oldest first, so walk it backwards
+	page := []*models.GoalPeriod{}
+	for i := total - 1 - offset; i >= 0 && len(page) < limit; i-- {
+		page = append(page, archive[i].Clone())
+	}
+	return page, total, nil
+}
+
+// categoryNameTaken reports whether userID has a category other than exceptID
+// whose name equals name, ignoring case. Must be called with db.mu held.
+func (db *InMemoryDB) categoryNameTaken(userID, name, exceptID string) bool {
+	for _, category := range db.categories {
+		if category.UserID == userID && category.ID != exceptID && strings.EqualFold(category.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateCategory adds a new category.
+// Parameters:
+//   - category: the category to store
+// Returns:
+//   - error: ErrCategoryExists if the user already has a category with that name
+func (db *InMemoryDB) CreateCategory(category *models.Category) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.categoryNameTaken(category.UserID, category.Name, category.ID) {
+		return ErrCategoryExists
+	}
+
+	stored := category.Clone()
+	if err := db.logMutation(walRecord{Op: opCreateCategory, Category: stored}); err != nil {
+		return err
+	}
+
+	db.putCategory(stored)
+	db.maybeSnapshot()
+	return nil
+}
+
+// GetCategoryByID retrieves a category by its ID.
+// Parameters:
+//   - id: the ID of the category
+// Returns:
+//   - *models.Category: a copy of the category
+//   - error: ErrCategoryNotFound if it doesn't exist
+func (db *InMemoryDB) GetCategoryByID(id string) (*models.Category, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	category, exists := db.categories[id]
+	if !exists {
+		return nil, ErrCategoryNotFound
+	}
+	return category.Clone(), nil
+}
+
+// ListCategories returns copies of the user's categories sorted by name.
+func (db *InMemoryDB) ListCategories(userID string) ([]*models.Category, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	categories := []*models.Category{}
+	for _, category := range db.categories {
+		if category.UserID == userID {
+			categories = append(categories, category.Clone())
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		a, b := strings.ToLower(categories[i].Name), strings.ToLower(categories[j].Name)
+		if a != b {
+			return a < b
+		}
+		return categories[i].ID < categories[j].ID
+	})
+	return categories, nil
+}
+
+// UpdateCategory saves the name, color and UpdatedAt of an existing category.
+// The owner and creation time are kept.
+// Parameters:
+//   - category: the category with its new values
+// Returns:
+//   - error: ErrCategoryNotFound, or ErrCategoryExists if the new name is taken
+func (db *InMemoryDB) UpdateCategory(category *models.Category) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, exists := db.categories[category.ID]
+	if !exists {
+		return ErrCategoryNotFound
+	}
+	if db.categoryNameTaken(stored.UserID, category.Name, stored.ID) {
+		return ErrCategoryExists
+	}
+
+	updated := stored.Clone()
+	updated.Name = category.Name
+	updated.Color = category.Color
+	updated.UpdatedAt = category.UpdatedAt
+
+	if err := db.logMutation(walRecord{Op: opUpdateCategory, Category: updated}); err != nil {
+		return err
+	}
+
+	db.putCategory(updated)
+	db.maybeSnapshot()
+	return nil
+}
+
+// DeleteCategory removes a category and takes its goals out of it.
+// Parameters:
+//   - id: the ID of the category
+// Returns:
+//   - error: ErrCategoryNotFound if it doesn't exist
+func (db *InMemoryDB) DeleteCategory(id string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.categories[id]; !exists {
+		return ErrCategoryNotFound
+	}
+
+	if err := db.logMutation(walRecord{Op: opDeleteCategory, ID: id}); err != nil {
+		return err
+	}
+
+	db.removeCategory(id)
+	db.maybeSnapshot()
+	return nil
+}
+
+// ContributionTotals sums each user's contributions to a goal.
+// Parameters:
+//   - goalID: the goal whose ledger to add up
+// Returns:
+//   - map[string]models.Money: the total per user ID (only users with contributions)
+//   - error: an error if amounts can't be added (mixed currencies)
+func (db *InMemoryDB) ContributionTotals(goalID string) (map[string]models.Money, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	totals := map[string]models.Money{}
+	for _, contribution := range db.contributions[goalID] {
+		total, exists := totals[contribution.UserID]
+		if !exists {
+			total = models.NewMoney(0, contribution.Amount.Currency)
+		}
+		sum, err := total.Add(contribution.Amount)
+		if err != nil {
+			return nil, err
+		}
+		totals[contribution.UserID] = sum
+	}
+	return totals, nil
+}
+
+// ContributionHistory returns a copy of a goal's whole ledger, oldest first.
+// Parameters:
+//   - goalID: the goal whose ledger to return
+// Returns:
+//   - []*models.Contribution: copies of all of the goal's contributions
+//   - error: always nil for the in-memory store
+func (db *InMemoryDB) ContributionHistory(goalID string) ([]*models.Contribution, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	ledger := db.contributions[goalID]
+	history := make([]*models.Contribution, len(ledger))
+	for i, contribution := range ledger {
+		history[i] = contribution.Clone()
+	}
+	return history, nil
+}
+
+// ListGoalMembers returns copies of the goal's members, in the order they joined.
+func (db *InMemoryDB) ListGoalMembers(goalID string) ([]*models.GoalMember, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	members := []*models.GoalMember{}
+	for _, member := range db.members[goalID] {
+		members = append(members, member.Clone())
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if !members[i].JoinedAt.Equal(members[j].JoinedAt) {
+			return members[i].JoinedAt.Before(members[j].JoinedAt)
+		}
+		return members[i].UserID < members[j].UserID
+	})
+	return members, nil
+}
+
+// GetGoalMember returns a copy of userID's membership of goalID.
+// Parameters:
+//   - goalID: the shared goal
+//   - userID: the member
+// Returns:
+//   - *models.GoalMember: the membership
+//   - error: ErrMemberNotFound if the goal isn't shared with the user
+func (db *InMemoryDB) GetGoalMember(goalID, userID string) (*models.GoalMember, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	member, exists := db.members[goalID][userID]
+	if !exists {
+		return nil, ErrMemberNotFound
+	}
+	return member.Clone(), nil
+}
+
+// UpdateGoalMember changes the role of an existing member.
+// Parameters:
+//   - member: the membership with its new role
+// Returns:
+//   - error: ErrMemberNotFound if the user isn't a member of the goal
+func (db *InMemoryDB) UpdateGoalMember(member *models.GoalMember) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, exists := db.members[member.GoalID][member.UserID]
+	if !exists {
+		return ErrMemberNotFound
+	}
+
+	updated := stored.Clone()
+	updated.Role = member.Role
+	if err := db.logMutation(walRecord{Op: opPutMember, Member: updated}); err != nil {
+		return err
+	}
+
+	db.putMember(updated)
+	db.maybeSnapshot()
+	return nil
+}
+
+// RemoveGoalMember stops sharing goalID with userID.
+// Returns ErrMemberNotFound if the user isn't a member of the goal.
+func (db *InMemoryDB) RemoveGoalMember(goalID, userID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.members[goalID][userID]; !exists {
+		return ErrMemberNotFound
+	}
+
+	member := &models.GoalMember{GoalID: goalID, UserID: userID}
+	if err := db.logMutation(walRecord{Op: opRemoveMember, Member: member}); err != nil {
+		return err
+	}
+
+	db.removeMember(goalID, userID)
+	db.maybeSnapshot()
+	return nil
+}
+
+// CreateInvitation stores a new pending invitation.
+// Parameters:
+//   - invitation: the invitation to store
+// Returns:
+//   - error: ErrGoalNotFound if the goal doesn't exist, ErrInvitationExists
+//     if the email already has a pending invitation to the goal
+func (db *InMemoryDB) CreateInvitation(invitation *models.Invitation) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.goals[invitation.GoalID]; !exists {
+		return ErrGoalNotFound
+	}
+	for _, other := range db.invitations {
+		if other.GoalID == invitation.GoalID && other.Email == invitation.Email && other.Status == models.InvitationPending {
+			return ErrInvitationExists
+		}
+	}
+
+	stored := invitation.Clone()
+	if err := db.logMutation(walRecord{Op: opPutInvitation, Invitation: stored}); err != nil {
+		return err
+	}
+
+	db.putInvitation(stored)
+	db.maybeSnapshot()
+	return nil
+}
+
+// GetInvitation returns a copy of the invitation with the given ID,
+// or ErrInvitationNotFound.
+func (db *InMemoryDB) GetInvitation(id string) (*models.Invitation, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	invitation, exists := db.invitations[id]
+	if !exists {
+		return nil, ErrInvitationNotFound
+	}
+	return invitation.Clone(), nil
+}
+
+// ListGoalInvitations returns the goal's pending invitations, oldest first.
+func (db *InMemoryDB) ListGoalInvitations(goalID string) ([]*models.Invitation, error) {
+	return db.listPendingInvitations(func(invitation *models.Invitation) bool {
+		return invitation.GoalID == goalID
+	}), nil
+}
+
+// ListInvitationsByEmail returns the pending invitations sent to email, oldest first.
+func (db *InMemoryDB) ListInvitationsByEmail(email string) ([]*models.Invitation, error) {
+	return db.listPendingInvitations(func(invitation *models.Invitation) bool {
+		return invitation.Email == email
+	}), nil
+}
+
+// listPendingInvitations returns copies of the pending invitations that
+// match, oldest first. Invitations are few, so it simply scans them all.
+func (db *InMemoryDB) listPendingInvitations(match func(invitation *models.Invitation) bool) []*models.Invitation {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	invitations := []*models.Invitation{}
+	for _, invitation := range db.invitations {
+		if invitation.Status == models.InvitationPending && match(invitation) {
+			invitations = append(invitations, invitation.Clone())
+		}
+	}
+	sort.Slice(invitations, func(i, j int) bool {
+		if !invitations[i].CreatedAt.Equal(invitations[j].CreatedAt) {
+			return invitations[i].CreatedAt.Before(invitations[j].CreatedAt)
+		}
+		return invitations[i].ID < invitations[j].ID
+	})
+	return invitations
+}
+
+// RespondToInvitation accepts or declines a pending invitation under the
+// write lock, so it can't be answered twice.
+// Parameters:
+//   - id: the invitation
+//   - userID: the invitee; on accept they become a member of the goal
+//   - accept: true to accept, false to decline
+//   - at: when the invitation was answered (the member's JoinedAt)
+// Returns:
+//   - *models.Invitation: a copy of the answered invitation
+//   - error: ErrInvitationNotFound, ErrInvitationClosed, ErrGoalNotFound
+//     if the goal was deleted in the meantime, or ErrInviteeIsOwner if the
+//     goal's owner tries to accept
+func (db *InMemoryDB) RespondToInvitation(id, userID string, accept bool, at time.Time) (*models.Invitation, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	stored, exists := db.invitations[id]
+	if !exists {
+		return nil, ErrInvitationNotFound
+	}
+	if stored.Status != models.InvitationPending {
+		return nil, ErrInvitationClosed
+	}
+	goal, exists := db.goals[stored.GoalID]
+	if !exists {
+		return nil, ErrGoalNotFound
+	}
+	if accept && goal.UserID == userID {
+		return nil, ErrInviteeIsOwner
+	}
+
+	invitation := stored.Clone()
+	invitation.Status = models.InvitationDeclined
+	invitation.RespondedAt = &at
+	var member *models.GoalMember
+	if accept {
+		invitation.Status = models.InvitationAccepted
+		member = &models.GoalMember{GoalID: invitation.GoalID, UserID: userID, Role: invitation.Role, JoinedAt: at}
+	}
+
+	if err := db.logMutation(walRecord{Op: opRespondInvitation, Invitation: invitation, Member: member}); err != nil {
+		return nil, err
+	}
+
+	db.putInvitation(invitation)
+	if member != nil {
+		db.putMember(member)
+	}
+	db.maybeSnapshot()
+	return invitation.Clone(), nil
+}
+
+// DeleteInvitation removes an invitation.
+// Returns ErrInvitationNotFound if it doesn't exist.
+func (db *InMemoryDB) DeleteInvitation(id string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.invitations[id]; !exists {
+		return ErrInvitationNotFound
+	}
+
+	if err := db.logMutation(walRecord{Op: opDeleteInvitation, ID: id}); err != nil {
+		return err
+	}
+
+	delete(db.invitations, id)
+	db.maybeSnapshot()
+	return nil
+}
+
+// SeedTemplates replaces the built-in templates with templates, logging only
+// the templates that actually change.
+// Parameters:
+//   - templates: every built-in template (their UserID must be empty)
+// Returns:
+//   - error: an error if the change can't be logged
+func (db *InMemoryDB) SeedTemplates(templates []*models.GoalTemplate) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	seeded := make(map[string]struct{}, len(templates))
+	for _, template := range templates {
+		seeded[template.ID] = struct{}{}
+
+		stored := template.Clone()
+		if existing, exists := db.templates[template.ID]; exists {
+			// A private template that happens to use the same ID is never replaced
+			if !existing.Builtin() {
+				continue
+			}
+			stored.CreatedAt = existing.CreatedAt
+			if sameTemplate(stored, existing) {
+				continue
+			}
+		}
+
+		if err := db.logMutation(walRecord{Op: opPutTemplate, Template: stored}); err != nil {
+			return err
+		}
+		db.putTemplate(stored)
+	}
+
+	for id, template := range db.templates {
+		if _, keep := seeded[id]; keep || !template.Builtin() {
+			continue
+		}
+		if err := db.logMutation(walRecord{Op: opDeleteTemplate, ID: id}); err != nil {
+			return err
+		}
+		delete(db.templates, id)
+	}
+
+	db.maybeSnapshot()
+	return nil
+}
+
+// sameTemplate reports whether a and b would be saved the same way.
+// They are compared as JSON, since an amount without a currency is saved
+// with DefaultCurrency and reads back with it.
+func sameTemplate(a, b *models.GoalTemplate) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(dataA) == string(dataB)
+}
+
+// CreateTemplate adds a user's private template.
+// Parameters:
+//   - template: the template to store
+// Returns:
+//   - error: ErrTemplateExists if a template with the same ID exists
+func (db *InMemoryDB) CreateTemplate(template *models.GoalTemplate) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.templates[template.ID]; exists {
+		return ErrTemplateExists
+	}
+
+	stored := template.Clone()
+	if err := db.logMutation(walRecord{Op: opPutTemplate, Template: stored}); err != nil {
+		return err
+	}
+
+	db.putTemplate(stored)
+	db.maybeSnapshot()
+	return nil
+}
+
+// GetTemplate retrieves a template by its ID.
+// Parameters:
+//   - id: the ID of the template
+// Returns:
+//   - *models.GoalTemplate: a copy of the template
+//   - error: ErrTemplateNotFound if it doesn't exist
+func (db *InMemoryDB) GetTemplate(id string) (*models.GoalTemplate, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	template, exists := db.templates[id]
+	if !exists {
+		return nil, ErrTemplateNotFound
+	}
+	return template.Clone(), nil
+}
+
+// ListTemplates returns copies of the built-in templates followed by the
+// user's own, each sorted by name.
+func (db *InMemoryDB) ListTemplates(userID string) ([]*models.GoalTemplate, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	templates := []*models.GoalTemplate{}
+	for _, template := range db.templates {
+		if template.Builtin() || template.UserID == userID {
+			templates = append(templates, template.Clone())
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		a, b := templates[i], templates[j]
+		if a.Builtin() != b.Builtin() {
+			return a.Builtin()
+		}
+		if nameA, nameB := strings.ToLower(a.Name), strings.ToLower(b.Name); nameA != nameB {
+			return nameA < nameB
+		}
+		return a.ID < b.ID
+	})
+	return templates, nil
+}
+
+// DeleteTemplate removes a template.
+// Parameters:
+//   - id: the ID of the template
+// Returns:
+//   - error: ErrTemplateNotFound if it doesn't exist
+func (db *InMemoryDB) DeleteTemplate(id string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.templates[id]; !exists {
+		return ErrTemplateNotFound
+	}
+
+	if err := db.logMutation(walRecord{Op: opDeleteTemplate, ID: id}); err != nil {
+		return err
+	}
 
+	delete(db.templates, id)
+	db.maybeSnapshot()
 	return nil
 }
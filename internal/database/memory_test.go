@@ -0,0 +1,232 @@
+package database
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go-api-server/internal/models"
+)
+
+// concurrentDeposits is how many goroutines deposit into one goal at once.
+const concurrentDeposits = 200
+
+// newTestGoal creates an open monthly goal in db and returns it.
+func newTestGoal(t testing.TB, db *InMemoryDB, id, userID string) *models.Goal {
+	t.Helper()
+	now := time.Now()
+	goal := &models.Goal{
+		ID:            id,
+		UserID:        userID,
+		Title:         "Goal " + id,
+		TargetAmount:  models.NewMoney(1_000_000_00, "USD"),
+		CurrentAmount: models.NewMoney(0, "USD"),
+		Duration:      models.Monthly,
+		StartDate:     now,
+		EndDate:       models.Monthly.EndDate(now),
+		CreatedAt:     now,
+	}
+	if err := db.CreateGoal(goal); err != nil {
+		t.Fatal(err)
+	}
+	return goal
+}
+
+// TestAddContributionConcurrent deposits into the same goal from many
+// goroutines; run with -race. Every deposit must be applied exactly once.
+func TestAddContributionConcurrent(t *testing.T) {
+	db := NewInMemoryDB()
+	goal := newTestGoal(t, db, "goal-1", "user-1")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, concurrentDeposits)
+	for i := 0; i < concurrentDeposits; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			contribution := &models.Contribution{
+				ID:        fmt.Sprintf("contribution-%d", i),
+				GoalID:    goal.ID,
+				UserID:    goal.UserID,
+				Kind:      models.ContributionDeposit,
+				Amount:    models.NewMoney(100, "USD"),
+				Source:    models.ContributionSourceManual,
+				CreatedAt: time.Now(),
+			}
+			_, err := db.AddContribution(contribution, func(goal *models.Goal) error { return nil })
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stored, err := db.GetGoalByID(goal.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(concurrentDeposits * 100); stored.CurrentAmount.Minor != want {
+		t.Errorf("CurrentAmount = %d, want %d", stored.CurrentAmount.Minor, want)
+	}
+	if want := int64(1 + concurrentDeposits); stored.Version != want {
+		t.Errorf("Version = %d, want %d", stored.Version, want)
+	}
+	ledger, err := db.ContributionHistory(goal.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledger) != concurrentDeposits {
+		t.Errorf("ledger has %d contributions, want %d", len(ledger), concurrentDeposits)
+	}
+}
+
+// TestUpdateGoalFuncConcurrent increments a goal from many goroutines with
+// UpdateGoalFunc; no read-modify-write may be lost.
+func TestUpdateGoalFuncConcurrent(t *testing.T) {
+	db := NewInMemoryDB()
+	goal := newTestGoal(t, db, "goal-1", "user-1")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, concurrentDeposits)
+	for i := 0; i < concurrentDeposits; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := db.UpdateGoalFunc(goal.ID, func(goal *models.Goal) error {
+				return goal.AddProgress(models.NewMoney(100, "USD"), time.Now())
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stored, err := db.GetGoalByID(goal.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(concurrentDeposits * 100); stored.CurrentAmount.Minor != want {
+		t.Errorf("CurrentAmount = %d, want %d", stored.CurrentAmount.Minor, want)
+	}
+	if want := int64(1 + concurrentDeposits); stored.Version != want {
+		t.Errorf("Version = %d, want %d", stored.Version, want)
+	}
+}
+
+// benchmarkUsers is how many users (each with one goal) the lookup
+// benchmarks seed the store with.
+const benchmarkUsers = 100_000
+
+var (
+	benchmarkOnce sync.Once
+	benchmarkDB   *InMemoryDB
+)
+
+// seededDB returns a store with benchmarkUsers users and one goal per user,
+// built once and shared by the benchmarks (they only read from it).
+func seededDB(b *testing.B) *InMemoryDB {
+	b.Helper()
+	benchmarkOnce.Do(func() {
+		db := NewInMemoryDB()
+		now := time.Now()
+		for i := 0; i < benchmarkUsers; i++ {
+			user := &models.User{
+				ID:        fmt.Sprintf("user-%d", i),
+				Email:     fmt.Sprintf("user-%d@example.com", i),
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			if err := db.CreateUser(user); err != nil {
+				b.Fatal(err)
+			}
+			newTestGoal(b, db, fmt.Sprintf("goal-%d", i), user.ID)
+		}
+		benchmarkDB = db
+	})
+	return benchmarkDB
+}
+
+// BenchmarkGetUserByID compares the usersByID index with scanning every
+// user, which is how GetUserByID looked users up before the index.
+func BenchmarkGetUserByID(b *testing.B) {
+	db := seededDB(b)
+	id := fmt.Sprintf("user-%d", benchmarkUsers/2)
+
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := db.GetUserByID(id); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var found *models.User
+			db.mu.RLock()
+			for _, user := range db.users {
+				if user.ID == id {
+					found = user.Clone()
+					break
+				}
+			}
+			db.mu.RUnlock()
+			if found == nil {
+				b.Fatal(ErrUserNotFound)
+			}
+		}
+	})
+}
+
+// BenchmarkGetUserByEmail looks users up by email, the primary key of the
+// users map, for comparison with BenchmarkGetUserByID.
+func BenchmarkGetUserByEmail(b *testing.B) {
+	db := seededDB(b)
+	email := fmt.Sprintf("user-%d@example.com", benchmarkUsers/2)
+
+	for i := 0; i < b.N; i++ {
+		if _, err := db.GetUserByEmail(email); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGetGoalsByUserID compares the goalsByUser index with scanning
+// every goal, which is how GetGoalsByUserID worked before the index.
+func BenchmarkGetGoalsByUserID(b *testing.B) {
+	db := seededDB(b)
+	userID := fmt.Sprintf("user-%d", benchmarkUsers/2)
+
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			goals, err := db.GetGoalsByUserID(userID)
+			if err != nil || len(goals) != 1 {
+				b.Fatalf("got %d goals, %v", len(goals), err)
+			}
+		}
+	})
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var goals []*models.Goal
+			db.mu.RLock()
+			for _, goal := range db.goals {
+				if goal.UserID == userID {
+					goals = append(goals, goal.Clone())
+				}
+			}
+			db.mu.RUnlock()
+			if len(goals) != 1 {
+				b.Fatalf("got %d goals", len(goals))
+			}
+		}
+	})
+}
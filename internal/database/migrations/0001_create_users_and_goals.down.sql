@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_goals_user_id;
+DROP TABLE IF EXISTS goals;
+DROP TABLE IF EXISTS users;
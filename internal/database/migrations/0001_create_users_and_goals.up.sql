@@ -0,0 +1,27 @@
+-- Initial schema for users and goals.
+-- IF NOT EXISTS keeps this safe for databases created before migrations
+-- were introduced (those already have these tables).
+
+CREATE TABLE IF NOT EXISTS users (
+	id         TEXT PRIMARY KEY,
+	email      TEXT NOT NULL UNIQUE,
+	password   TEXT NOT NULL,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS goals (
+	id             TEXT PRIMARY KEY,
+	user_id        TEXT NOT NULL,
+	title          TEXT NOT NULL,
+	target_amount  REAL NOT NULL,
+	current_amount REAL NOT NULL DEFAULT 0,
+	duration       TEXT NOT NULL,
+	start_date     TEXT NOT NULL,
+	end_date       TEXT NOT NULL,
+	completed      INTEGER NOT NULL DEFAULT 0,
+	completed_at   TEXT,
+	created_at     TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_goals_user_id ON goals(user_id);
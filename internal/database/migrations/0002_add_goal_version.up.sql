@@ -0,0 +1,4 @@
+-- Version counter for optimistic concurrency control.
+-- Existing goals start at version 1, the same as newly created ones.
+
+ALTER TABLE goals ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_contributions_goal_id;
+DROP TABLE IF EXISTS contributions;
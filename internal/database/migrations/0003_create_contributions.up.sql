@@ -0,0 +1,21 @@
+-- Ledger of every progress update. Rows are never updated, only inserted,
+-- and are removed together with their goal.
+
+CREATE TABLE contributions (
+	id         TEXT PRIMARY KEY,
+	goal_id    TEXT NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+	user_id    TEXT NOT NULL,
+	amount     REAL NOT NULL,
+	note       TEXT NOT NULL DEFAULT '',
+	source     TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_contributions_goal_id ON contributions(goal_id, created_at);
+
+-- Goals saved before the ledger existed already have progress. Record it as
+-- an opening balance so current_amount matches the sum of the ledger.
+INSERT INTO contributions (id, goal_id, user_id, amount, note, source, created_at)
+SELECT 'opening-' || id, id, user_id, current_amount, 'Balance before the contribution ledger', 'opening_balance', created_at
+FROM goals
+WHERE current_amount <> 0;
@@ -0,0 +1,2 @@
+ALTER TABLE contributions DROP COLUMN reason;
+ALTER TABLE contributions DROP COLUMN kind;
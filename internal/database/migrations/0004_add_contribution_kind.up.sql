@@ -0,0 +1,5 @@
+-- Withdrawals and corrections are stored in the ledger next to deposits.
+-- Every contribution recorded so far was a deposit.
+
+ALTER TABLE contributions ADD COLUMN kind TEXT NOT NULL DEFAULT 'deposit';
+ALTER TABLE contributions ADD COLUMN reason TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+-- Amounts are converted back assuming two decimal places.
+
+ALTER TABLE contributions ADD COLUMN amount REAL NOT NULL DEFAULT 0;
+UPDATE contributions SET amount = amount_minor / 100.0;
+ALTER TABLE contributions DROP COLUMN amount_minor;
+ALTER TABLE contributions DROP COLUMN currency;
+
+ALTER TABLE goals ADD COLUMN target_amount REAL NOT NULL DEFAULT 0;
+ALTER TABLE goals ADD COLUMN current_amount REAL NOT NULL DEFAULT 0;
+UPDATE goals SET
+	target_amount  = target_minor / 100.0,
+	current_amount = current_minor / 100.0;
+ALTER TABLE goals DROP COLUMN current_minor;
+ALTER TABLE goals DROP COLUMN target_minor;
+ALTER TABLE goals DROP COLUMN currency;
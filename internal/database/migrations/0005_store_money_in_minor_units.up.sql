@@ -0,0 +1,16 @@
+-- Store amounts as exact integers of minor units (e.g. cents) plus a currency
+-- code instead of floating point. Every amount so far was in USD.
+
+ALTER TABLE goals ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD';
+ALTER TABLE goals ADD COLUMN target_minor INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE goals ADD COLUMN current_minor INTEGER NOT NULL DEFAULT 0;
+UPDATE goals SET
+	target_minor  = CAST(ROUND(target_amount * 100) AS INTEGER),
+	current_minor = CAST(ROUND(current_amount * 100) AS INTEGER);
+ALTER TABLE goals DROP COLUMN target_amount;
+ALTER TABLE goals DROP COLUMN current_amount;
+
+ALTER TABLE contributions ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD';
+ALTER TABLE contributions ADD COLUMN amount_minor INTEGER NOT NULL DEFAULT 0;
+UPDATE contributions SET amount_minor = CAST(ROUND(amount * 100) AS INTEGER);
+ALTER TABLE contributions DROP COLUMN amount;
@@ -0,0 +1,3 @@
+-- Each user picks the currency for new goals and converted totals.
+
+ALTER TABLE users ADD COLUMN default_currency TEXT NOT NULL DEFAULT 'USD';
@@ -0,0 +1,2 @@
+-- Fixed-width timestamps are still valid RFC 3339, so only the index is removed.
+DROP INDEX IF EXISTS idx_goals_user_created;
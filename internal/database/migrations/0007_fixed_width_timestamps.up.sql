@@ -0,0 +1,40 @@
+-- Timestamps used to be written with time.RFC3339Nano, which drops trailing
+-- zeros ("...:04Z", "...:04.5Z"), so comparing them as TEXT was not
+-- chronological. Rewrite them with exactly nine fractional digits
+-- ("...:04.500000000Z"), the format the application now writes, so that
+-- ORDER BY and range filters on these columns are correct.
+-- All stored timestamps are UTC ("Z"); the first 19 characters are the
+-- seconds, and the fraction (if any) starts after the "." at position 20.
+
+UPDATE users SET
+	created_at = CASE WHEN instr(created_at, '.') = 0
+		THEN substr(created_at, 1, 19) || '.000000000Z'
+		ELSE substr(created_at, 1, 20) || substr(substr(created_at, 21, length(created_at) - 21) || '000000000', 1, 9) || 'Z' END,
+	updated_at = CASE WHEN instr(updated_at, '.') = 0
+		THEN substr(updated_at, 1, 19) || '.000000000Z'
+		ELSE substr(updated_at, 1, 20) || substr(substr(updated_at, 21, length(updated_at) - 21) || '000000000', 1, 9) || 'Z' END;
+
+UPDATE goals SET
+	start_date = CASE WHEN instr(start_date, '.') = 0
+		THEN substr(start_date, 1, 19) || '.000000000Z'
+		ELSE substr(start_date, 1, 20) || substr(substr(start_date, 21, length(start_date) - 21) || '000000000', 1, 9) || 'Z' END,
+	end_date = CASE WHEN instr(end_date, '.') = 0
+		THEN substr(end_date, 1, 19) || '.000000000Z'
+		ELSE substr(end_date, 1, 20) || substr(substr(end_date, 21, length(end_date) - 21) || '000000000', 1, 9) || 'Z' END,
+	created_at = CASE WHEN instr(created_at, '.') = 0
+		THEN substr(created_at, 1, 19) || '.000000000Z'
+		ELSE substr(created_at, 1, 20) || substr(substr(created_at, 21, length(created_at) - 21) || '000000000', 1, 9) || 'Z' END;
+
+UPDATE goals SET
+	completed_at = CASE WHEN instr(completed_at, '.') = 0
+		THEN substr(completed_at, 1, 19) || '.000000000Z'
+		ELSE substr(completed_at, 1, 20) || substr(substr(completed_at, 21, length(completed_at) - 21) || '000000000', 1, 9) || 'Z' END
+WHERE completed_at IS NOT NULL;
+
+UPDATE contributions SET
+	created_at = CASE WHEN instr(created_at, '.') = 0
+		THEN substr(created_at, 1, 19) || '.000000000Z'
+		ELSE substr(created_at, 1, 20) || substr(substr(created_at, 21, length(created_at) - 21) || '000000000', 1, 9) || 'Z' END;
+
+-- GET /goals pages through a user's goals ordered by created_at by default
+CREATE INDEX IF NOT EXISTS idx_goals_user_created ON goals(user_id, created_at, id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_goals_rollover;
+DROP TABLE IF EXISTS goal_periods;
+ALTER TABLE goals DROP COLUMN period;
+ALTER TABLE goals DROP COLUMN recurring;
@@ -0,0 +1,23 @@
+-- Recurring goals start a new period when end_date passes; each finished
+-- period is archived in goal_periods with its final amount and outcome.
+
+ALTER TABLE goals ADD COLUMN recurring INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE goals ADD COLUMN period INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE goal_periods (
+	goal_id      TEXT NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+	number       INTEGER NOT NULL,
+	user_id      TEXT NOT NULL,
+	currency     TEXT NOT NULL,
+	target_minor INTEGER NOT NULL,
+	final_minor  INTEGER NOT NULL,
+	start_date   TEXT NOT NULL,
+	end_date     TEXT NOT NULL,
+	succeeded    INTEGER NOT NULL,
+	completed_at TEXT,
+	archived_at  TEXT NOT NULL,
+	PRIMARY KEY (goal_id, number)
+);
+
+-- The rollover job looks for recurring goals whose end_date has passed
+CREATE INDEX idx_goals_rollover ON goals(end_date) WHERE recurring = 1;
@@ -0,0 +1,3 @@
+-- Goal periods start and end at midnight in the user's time zone.
+
+ALTER TABLE users ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,2 @@
+ALTER TABLE goal_periods DROP COLUMN milestones;
+ALTER TABLE goals DROP COLUMN milestones;
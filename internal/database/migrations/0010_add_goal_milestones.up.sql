@@ -0,0 +1,5 @@
+-- Milestones are stored inline on each goal (and archived period) as a JSON
+-- array, e.g. [{"percent":50,"threshold":{...},"reached_at":"..."}].
+
+ALTER TABLE goals ADD COLUMN milestones TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE goal_periods ADD COLUMN milestones TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN streaks;
+ALTER TABLE goals DROP COLUMN streaks;
@@ -0,0 +1,5 @@
+-- Daily and weekly saving streaks, stored inline as JSON like milestones,
+-- e.g. {"daily":{"current":3,"longest":5,"last_active":"2024-03-18"},"weekly":{...}}.
+
+ALTER TABLE goals ADD COLUMN streaks TEXT NOT NULL DEFAULT '{}';
+ALTER TABLE users ADD COLUMN streaks TEXT NOT NULL DEFAULT '{}';
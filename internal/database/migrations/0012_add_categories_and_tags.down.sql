@@ -0,0 +1,4 @@
+ALTER TABLE goals DROP COLUMN tags;
+DROP INDEX idx_goals_category_id;
+ALTER TABLE goals DROP COLUMN category_id;
+DROP TABLE categories;
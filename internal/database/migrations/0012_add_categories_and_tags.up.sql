@@ -0,0 +1,22 @@
+-- User-defined goal categories, and free-form tags on goals.
+-- goals.category_id has no foreign key so the column can be dropped again;
+-- DeleteCategory clears it on the category's goals itself.
+
+CREATE TABLE categories (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	name       TEXT NOT NULL,
+	color      TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+
+-- Category names are unique per user, ignoring case
+CREATE UNIQUE INDEX idx_categories_user_name ON categories(user_id, name COLLATE NOCASE);
+
+ALTER TABLE goals ADD COLUMN category_id TEXT;
+CREATE INDEX idx_goals_category_id ON goals(category_id);
+
+-- Tags are a sorted JSON array of lowercase strings, e.g. ["2025","travel"],
+-- matched with json_each when filtering
+ALTER TABLE goals ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
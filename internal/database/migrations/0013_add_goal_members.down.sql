@@ -0,0 +1,3 @@
+DROP INDEX idx_contributions_goal_user;
+DROP TABLE goal_invitations;
+DROP TABLE goal_members;
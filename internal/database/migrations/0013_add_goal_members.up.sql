@@ -0,0 +1,31 @@
+-- Shared goals: the users a goal is shared with (its owner stays goals.user_id)
+-- and the invitations sent to join it. Both go away with their goal.
+
+CREATE TABLE goal_members (
+	goal_id   TEXT NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+	user_id   TEXT NOT NULL,
+	role      TEXT NOT NULL,
+	joined_at TEXT NOT NULL,
+	PRIMARY KEY (goal_id, user_id)
+);
+
+-- GET /goals lists the goals shared with a user as well as their own
+CREATE INDEX idx_goal_members_user_id ON goal_members(user_id);
+
+CREATE TABLE goal_invitations (
+	id           TEXT PRIMARY KEY,
+	goal_id      TEXT NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+	email        TEXT NOT NULL,
+	role         TEXT NOT NULL,
+	invited_by   TEXT NOT NULL,
+	status       TEXT NOT NULL,
+	created_at   TEXT NOT NULL,
+	responded_at TEXT
+);
+
+-- At most one pending invitation per goal and email
+CREATE UNIQUE INDEX idx_goal_invitations_pending ON goal_invitations(goal_id, email) WHERE status = 'pending';
+CREATE INDEX idx_goal_invitations_email ON goal_invitations(email, created_at);
+
+-- Summing the ledger per member
+CREATE INDEX idx_contributions_goal_user ON contributions(goal_id, user_id);
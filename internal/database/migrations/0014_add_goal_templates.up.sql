@@ -0,0 +1,21 @@
+-- Goal templates: built-in ones (user_id NULL, seeded from the templates
+-- package on startup) and users' private ones. currency is '' for targets
+-- without a currency, which goals made from the template resolve into the
+-- user's default currency.
+
+CREATE TABLE goal_templates (
+	id           TEXT PRIMARY KEY,
+	user_id      TEXT,
+	name         TEXT NOT NULL,
+	description  TEXT NOT NULL DEFAULT '',
+	title        TEXT NOT NULL,
+	currency     TEXT NOT NULL DEFAULT '',
+	target_minor INTEGER NOT NULL,
+	duration     TEXT NOT NULL,
+	recurring    INTEGER NOT NULL DEFAULT 0,
+	milestones   TEXT NOT NULL DEFAULT '[]',
+	tags         TEXT NOT NULL DEFAULT '[]',
+	created_at   TEXT NOT NULL
+);
+
+CREATE INDEX idx_goal_templates_user_id ON goal_templates(user_id);
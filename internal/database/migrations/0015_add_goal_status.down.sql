@@ -0,0 +1,5 @@
+DROP INDEX idx_goals_deleted_at;
+ALTER TABLE goals DROP COLUMN deleted_at;
+ALTER TABLE goals DROP COLUMN archived_at;
+ALTER TABLE goals DROP COLUMN paused_at;
+ALTER TABLE goals DROP COLUMN status;
@@ -0,0 +1,11 @@
+-- Goal life cycle: active, paused or archived, plus soft deletion.
+-- Deleted goals keep their rows (and status) until the purge job removes
+-- them for good, so they can be restored in the meantime.
+
+ALTER TABLE goals ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
+ALTER TABLE goals ADD COLUMN paused_at TEXT;
+ALTER TABLE goals ADD COLUMN archived_at TEXT;
+ALTER TABLE goals ADD COLUMN deleted_at TEXT;
+
+-- The purge job looks up goals deleted before the retention window
+CREATE INDEX idx_goals_deleted_at ON goals(deleted_at);
@@ -0,0 +1,4 @@
+-- Emails are matched case-insensitively: new accounts store them lowercased
+-- (see models.NormalizeEmail) and lookups compare lower(email), which also
+-- finds accounts registered with mixed case before that.
+CREATE INDEX idx_users_email_lower ON users(lower(email));
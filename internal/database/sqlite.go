@@ -0,0 +1,1507 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-api-server/internal/models"
+
+	// Pure-Go SQLite driver (no cgo needed). It registers itself as "sqlite".
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteDB is a Store implementation backed by an embedded SQLite database file.
+// Unlike InMemoryDB, data survives server restarts.
+type SQLiteDB struct {
+	// db is the connection pool managed by database/sql
+	db *sql.DB
+}
+
+// Compile-time check that SQLiteDB satisfies the Store interface.
+var _ Store = (*SQLiteDB)(nil)
+
+// NewSQLiteDB opens (or creates) the SQLite database at path.
+// The tables are created by the migrations (see Migrator), which main.go runs
+// on boot when DB_AUTO_MIGRATE is set, or manually with "migrate up".
+// Parameters:
+//   - path: file path of the database, e.g. "data.db"
+//
+// Returns:
+//   - *SQLiteDB: the ready-to-use store
+//   - error: nil if successful, error if the file can't be opened
+func NewSQLiteDB(path string) (*SQLiteDB, error) {
+	// busy_timeout makes concurrent writers wait instead of failing immediately,
+	// foreign_keys is off by default in SQLite so we turn it on explicitly
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows only one writer at a time, so a single connection avoids
+	// "database is locked" errors and serializes transactions for us
+	db.SetMaxOpenConns(1)
+
+	// sql.Open doesn't connect yet, so ping to surface a bad path right away
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteDB{db: db}, nil
+}
+
+// Migrator returns a Migrator for this database's schema.
+func (s *SQLiteDB) Migrator() (*Migrator, error) {
+	return NewMigrator(s.db)
+}
+
+// Close releases the underlying database connection.
+func (s *SQLiteDB) Close() error {
+	return s.db.Close()
+}
+
+// withTx runs fn inside a transaction, committing on success and rolling back on error.
+func withTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx, so query helpers can
+// run either on their own or as part of a larger transaction.
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows,
+// so the scan helpers below work for single-row and multi-row queries.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// timeLayout is RFC 3339 in UTC with exactly nine fractional digits.
+// Unlike time.RFC3339Nano (which drops trailing zeros) every timestamp has the
+// same width, so comparing and ordering the TEXT columns is chronological.
+const timeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// formatTime converts a time to the text format stored in the database.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// parseTime converts a stored timestamp back into a time.Time.
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// userColumns is the column list used by every user SELECT, in scanUser order.
+const userColumns = `id, email, password, default_currency, time_zone, created_at, updated_at, streaks`
+
+// scanUser reads one users row into a models.User.
+func scanUser(row rowScanner) (*models.User, error) {
+	var (
+		user                 models.User
+		createdAt, updatedAt string
+		streaks              string
+	)
+	if err := row.Scan(&user.ID, &user.Email, &user.Password, &user.DefaultCurrency, &user.TimeZone, &createdAt, &updatedAt, &streaks); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(streaks), &user.Streaks); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if user.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if user.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateUser inserts a new user.
+// It returns ErrUserExists if the email is already registered.
+func (s *SQLiteDB) CreateUser(user *models.User) error {
+	// ON CONFLICT DO NOTHING lets us detect duplicates without depending on
+	// driver-specific error types: a duplicate simply inserts zero rows
+	res, err := s.db.Exec(
+		`INSERT INTO users (`+userColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`,
+		user.ID, user.Email, user.Password, user.DefaultCurrency, user.TimeZone,
+		formatTime(user.CreatedAt), formatTime(user.UpdatedAt), streaksJSON(user.Streaks),
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrUserExists
+	}
+	return nil
+}
+
+// GetUserByEmail retrieves a user by email address, ignoring case.
+// It returns ErrUserNotFound if no user has that email.
+func (s *SQLiteDB) GetUserByEmail(email string) (*models.User, error) {
+	row := s.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE lower(email) = ?`, models.NormalizeEmail(email))
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+// GetUserByID retrieves a user by ID.
+// It returns ErrUserNotFound if no user has that ID.
+func (s *SQLiteDB) GetUserByID(id string) (*models.User, error) {
+	row := s.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE id = ?`, id)
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+// UpdateUser saves the password, default currency, time zone and updated_at
+// of the user with user.ID. The email is never changed, and the streaks only
+// through UpdateUserStreaks.
+// It returns ErrUserNotFound if no user has that ID.
+func (s *SQLiteDB) UpdateUser(user *models.User) error {
+	res, err := s.db.Exec(
+		`UPDATE users SET password = ?, default_currency = ?, time_zone = ?, updated_at = ? WHERE id = ?`,
+		user.Password, user.DefaultCurrency, user.TimeZone, formatTime(user.UpdatedAt), user.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res, ErrUserNotFound)
+}
+
+// UpdateUserStreaks reads, modifies and saves a user's streaks inside a transaction.
+// If fn returns an error the transaction is rolled back and the error returned.
+func (s *SQLiteDB) UpdateUserStreaks(id string, fn func(streaks *models.Streaks) error) (*models.Streaks, error) {
+	var updated models.Streaks
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		var raw string
+		err := tx.QueryRow(`SELECT streaks FROM users WHERE id = ?`, id).Scan(&raw)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(raw), &updated); err != nil {
+			return err
+		}
+		if err := fn(&updated); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE users SET streaks = ? WHERE id = ?`, streaksJSON(updated), id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteUser removes a user by email, ignoring case.
+// It returns ErrUserNotFound if no user has that email.
+func (s *SQLiteDB) DeleteUser(email string) error {
+	res, err := s.db.Exec(`DELETE FROM users WHERE lower(email) = ?`, models.NormalizeEmail(email))
+	if err != nil {
+		return err
+	}
+	return requireAffected(res, ErrUserNotFound)
+}
+
+// GetAllUsers returns every user, oldest first.
+func (s *SQLiteDB) GetAllUsers() ([]*models.User, error) {
+	rows, err := s.db.Query(`SELECT ` + userColumns + ` FROM users ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// goalColumns is the column list used by every goal SELECT, in scanGoal order.
+// Amounts are stored as integer minor units; both share the goal's currency.
+const goalColumns = `id, user_id, title, currency, target_minor, current_minor, duration,
+	start_date, end_date, completed, completed_at, created_at, version, recurring, period, milestones, streaks,
+	category_id, tags, status, paused_at, archived_at, deleted_at`
+
+// scanGoal reads one goals row into a models.Goal.
+func scanGoal(row rowScanner) (*models.Goal, error) {
+	var (
+		goal                            models.Goal
+		currency                        string
+		targetMinor, currentMinor       int64
+		startDate, endDate, createdAt   string
+		completedAt, categoryID         sql.NullString
+		pausedAt, archivedAt, deletedAt sql.NullString
+		milestones, streaks, tags       string
+	)
+	err := row.Scan(
+		&goal.ID, &goal.UserID, &goal.Title, &currency, &targetMinor, &currentMinor, &goal.Duration,
+		&startDate, &endDate, &goal.Completed, &completedAt, &createdAt, &goal.Version, &goal.Recurring, &goal.Period,
+		&milestones, &streaks, &categoryID, &tags, &goal.Status, &pausedAt, &archivedAt, &deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(milestones), &goal.Milestones); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(streaks), &goal.Streaks); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tags), &goal.Tags); err != nil {
+		return nil, err
+	}
+	goal.CategoryID = categoryID.String
+
+	goal.TargetAmount = models.NewMoney(targetMinor, currency)
+	goal.CurrentAmount = models.NewMoney(currentMinor, currency)
+
+	if goal.StartDate, err = parseTime(startDate); err != nil {
+		return nil, err
+	}
+	if goal.EndDate, err = parseTime(endDate); err != nil {
+		return nil, err
+	}
+	if goal.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if goal.CompletedAt, err = parseNullableTime(completedAt); err != nil {
+		return nil, err
+	}
+	if goal.PausedAt, err = parseNullableTime(pausedAt); err != nil {
+		return nil, err
+	}
+	if goal.ArchivedAt, err = parseNullableTime(archivedAt); err != nil {
+		return nil, err
+	}
+	if goal.DeletedAt, err = parseNullableTime(deletedAt); err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// milestonesJSON encodes milestones for their TEXT column ("[]" when there are none).
+// Milestones are small and always read with their goal, so they are stored
+// inline as JSON instead of in a table of their own.
+func milestonesJSON(milestones []models.Milestone) string {
+	if len(milestones) == 0 {
+		return "[]"
+	}
+	data, _ := json.Marshal(milestones)
+	return string(data)
+}
+
+// streaksJSON encodes streaks for their TEXT column.
+func streaksJSON(streaks models.Streaks) string {
+	data, _ := json.Marshal(streaks)
+	return string(data)
+}
+
+// tagsJSON encodes tags for their TEXT column ("[]" when there are none).
+func tagsJSON(tags []string) string {
+	if len(tags) == 0 {
+		return "[]"
+	}
+	data, _ := json.Marshal(tags)
+	return string(data)
+}
+
+// nullableString stores an empty string as NULL (e.g. a goal without a category).
+func nullableString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullableTime converts an optional time into a value SQLite can store (NULL when nil).
+func nullableTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*t), Valid: true}
+}
+
+// parseNullableTime reads an optional time written by nullableTime (nil for NULL).
+func parseNullableTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := parseTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// CreateGoal inserts a new goal at version 1, active unless it has a status.
+// It returns ErrGoalExists if a goal with the same ID already exists.
+func (s *SQLiteDB) CreateGoal(goal *models.Goal) error {
+	goal.Version = 1
+	if goal.Status == "" {
+		goal.Status = models.GoalActive
+	}
+	res, err := s.db.Exec(
+		`INSERT INTO goals (`+goalColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`,
+		goal.ID, goal.UserID, goal.Title, goal.Currency(), goal.TargetAmount.Minor, goal.CurrentAmount.Minor, goal.Duration,
+		formatTime(goal.StartDate), formatTime(goal.EndDate), goal.Completed, nullableTime(goal.CompletedAt),
+		formatTime(goal.CreatedAt), goal.Version, goal.Recurring, goal.Period, milestonesJSON(goal.Milestones),
+		streaksJSON(goal.Streaks), nullableString(goal.CategoryID), tagsJSON(goal.Tags),
+		goal.Status, nullableTime(goal.PausedAt), nullableTime(goal.ArchivedAt), nullableTime(goal.DeletedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrGoalExists
+	}
+	return nil
+}
+
+// GetGoalsByUserID returns all goals owned by userID, oldest first.
+func (s *SQLiteDB) GetGoalsByUserID(userID string) ([]*models.Goal, error) {
+	rows, err := s.db.Query(`SELECT `+goalColumns+` FROM goals WHERE user_id = ? ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var goals []*models.Goal
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, goal)
+	}
+	return goals, rows.Err()
+}
+
+// progressSQL computes Goal.ProgressBasisPoints in SQL (integer division),
+// with the same branches so no product overflows into a REAL.
+// 922337203685477 is math.MaxInt64 / 10000.
+const progressSQL = `(CASE
+	WHEN target_minor <= 0 OR current_minor <= 0 THEN 0
+	WHEN current_minor <= 922337203685477 THEN current_minor * 10000 / target_minor
+	WHEN target_minor <= 922337203685477 THEN
+		CASE WHEN current_minor / target_minor >= 922337203685477 THEN 9223372036854770000
+		ELSE current_minor / target_minor * 10000 + current_minor % target_minor * 10000 / target_minor END
+	ELSE current_minor / (target_minor / 10000)
+END)`
+
+// ListGoals returns one page of a user's goals matching query.
+// Filters, ordering and the cursor are applied in SQL, so only one page of
+// rows (plus one to detect the next page) is read.
+func (s *SQLiteDB) ListGoals(query models.GoalQuery) (*models.GoalPage, error) {
+	query = normalizeGoalQuery(query)
+	cursor, err := decodeGoalCursor(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// The user's own goals and the ones shared with them
+	where := []string{"(user_id = ? OR id IN (SELECT goal_id FROM goal_members WHERE user_id = ?))"}
+	args := []interface{}{query.UserID, query.UserID}
+	addFilter := func(clause string, arg interface{}) {
+		where = append(where, clause)
+		args = append(args, arg)
+	}
+	if query.Shared != nil {
+		if *query.Shared {
+			addFilter("user_id <> ?", query.UserID)
+		} else {
+			addFilter("user_id = ?", query.UserID)
+		}
+	}
+	switch query.Status {
+	case "":
+		addFilter("deleted_at IS NULL AND status <> ?", models.GoalArchived)
+	case models.GoalStatusDeleted:
+		where = append(where, "deleted_at IS NOT NULL")
+	default:
+		addFilter("deleted_at IS NULL AND status = ?", query.Status)
+	}
+	if query.Completed != nil {
+		addFilter("completed = ?", *query.Completed)
+	}
+	if query.Duration != "" {
+		addFilter("duration = ?", query.Duration)
+	}
+	if query.Search != "" {
+		addFilter("instr(lower(title), lower(?)) > 0", query.Search)
+	}
+	if query.Category != "" {
+		addFilter("category_id = ?", query.Category)
+	}
+	for _, tag := range query.Tags {
+		addFilter("EXISTS (SELECT 1 FROM json_each(goals.tags) WHERE value = ?)", tag)
+	}
+	if query.CreatedAfter != nil {
+		addFilter("created_at >= ?", formatTime(*query.CreatedAfter))
+	}
+	if query.CreatedBefore != nil {
+		addFilter("created_at <= ?", formatTime(*query.CreatedBefore))
+	}
+	if query.EndAfter != nil {
+		addFilter("end_date >= ?", formatTime(*query.EndAfter))
+	}
+	if query.EndBefore != nil {
+		addFilter("end_date <= ?", formatTime(*query.EndBefore))
+	}
+
+	// Only these fixed expressions are ever interpolated into the SQL
+	sortExpr := "created_at"
+	switch query.Sort {
+	case models.GoalSortEndDate:
+		sortExpr = "end_date"
+	case models.GoalSortProgress:
+		sortExpr = progressSQL
+	}
+	direction, after := "ASC", ">"
+	if query.Descending() {
+		direction, after = "DESC", "<"
+	}
+
+	// Resume strictly after the cursor's (sort key, id) position
+	if cursor != nil {
+		var key interface{} = cursor.Key
+		if query.Sort == models.GoalSortProgress {
+			key, _ = strconv.ParseInt(cursor.Key, 10, 64)
+		}
+		where = append(where, fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", sortExpr, after, sortExpr, after))
+		args = append(args, key, key, cursor.ID)
+	}
+
+	args = append(args, query.Limit+1)
+	rows, err := s.db.Query(
+		`SELECT `+goalColumns+` FROM goals WHERE `+strings.Join(where, " AND ")+
+			fmt.Sprintf(` ORDER BY %s %s, id %s LIMIT ?`, sortExpr, direction, direction),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &models.GoalPage{Goals: []*models.Goal{}}
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		page.Goals = append(page.Goals, goal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(page.Goals) > query.Limit {
+		page.Goals = page.Goals[:query.Limit]
+		page.NextCursor = encodeGoalCursor(query, page.Goals[query.Limit-1])
+	}
+	return page, nil
+}
+
+// GetGoalByID retrieves a goal by ID.
+// It returns ErrGoalNotFound if no goal has that ID.
+func (s *SQLiteDB) GetGoalByID(id string) (*models.Goal, error) {
+	return getGoal(s.db, id)
+}
+
+// UpdateGoal overwrites the stored goal with the given one if its version
+// still matches, and increments the version.
+// It returns ErrGoalNotFound if the goal doesn't exist and
+// ErrVersionConflict if goal.Version is stale.
+func (s *SQLiteDB) UpdateGoal(goal *models.Goal) error {
+	return updateGoal(s.db, goal)
+}
+
+// UpdateGoalFunc atomically reads, modifies and saves a goal inside a
+// transaction. Because the store uses a single connection, concurrent
+// transactions run one after another and no update can be lost.
+// If fn returns an error the transaction is rolled back and the error returned.
+func (s *SQLiteDB) UpdateGoalFunc(id string, fn func(goal *models.Goal) error) (*models.Goal, error) {
+	var updated *models.Goal
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		goal, err := getGoal(tx, id)
+		if err != nil {
+			return err
+		}
+		current := goal.Version
+		if err := fn(goal); err != nil {
+			return err
+		}
+		// fn can't move the goal or rewrite its version; we read the
+		// current version inside this transaction so the check always passes
+		goal.ID = id
+		goal.Version = current
+		if err := updateGoal(tx, goal); err != nil {
+			return err
+		}
+		updated = goal
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// getGoal loads one goal using either the pool or a transaction.
+func getGoal(q querier, id string) (*models.Goal, error) {
+	row := q.QueryRow(`SELECT `+goalColumns+` FROM goals WHERE id = ?`, id)
+	goal, err := scanGoal(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrGoalNotFound
+	}
+	return goal, err
+}
+
+// updateGoal writes every column of goal using either the pool or a transaction.
+// The WHERE clause includes the version the caller read, so the write only
+// happens if nobody saved the goal in between (compare-and-swap).
+// On success goal.Version is set to the new version.
+func updateGoal(q querier, goal *models.Goal) error {
+	res, err := q.Exec(
+		`UPDATE goals SET user_id = ?, title = ?, currency = ?, target_minor = ?, current_minor = ?, duration = ?,
+			start_date = ?, end_date = ?, completed = ?, completed_at = ?, created_at = ?, recurring = ?, period = ?,
+			milestones = ?, streaks = ?, category_id = ?, tags = ?, status = ?, paused_at = ?, archived_at = ?,
+			deleted_at = ?, version = version + 1
+		WHERE id = ? AND version = ?`,
+		goal.UserID, goal.Title, goal.Currency(), goal.TargetAmount.Minor, goal.CurrentAmount.Minor, goal.Duration,
+		formatTime(goal.StartDate), formatTime(goal.EndDate), goal.Completed, nullableTime(goal.CompletedAt),
+		formatTime(goal.CreatedAt), goal.Recurring, goal.Period, milestonesJSON(goal.Milestones), streaksJSON(goal.Streaks),
+		nullableString(goal.CategoryID), tagsJSON(goal.Tags), goal.Status, nullableTime(goal.PausedAt),
+		nullableTime(goal.ArchivedAt), nullableTime(goal.DeletedAt), goal.ID, goal.Version,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		// Zero rows means either the goal is gone or its version moved on;
+		// look it up to tell the two apart
+		var exists bool
+		if err := q.QueryRow(`SELECT EXISTS(SELECT 1 FROM goals WHERE id = ?)`, goal.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrGoalNotFound
+		}
+		return ErrVersionConflict
+	}
+
+	goal.Version++
+	return nil
+}
+
+// DeleteGoal removes a goal by ID; its contributions, periods, members and
+// invitations are removed by ON DELETE CASCADE.
+// It returns ErrGoalNotFound if the goal doesn't exist.
+func (s *SQLiteDB) DeleteGoal(id string) error {
+	res, err := s.db.Exec(`DELETE FROM goals WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res, ErrGoalNotFound)
+}
+
+// PurgeDeletedGoals removes the goals deleted before deletedBefore; their
+// contributions, periods, members and invitations go with them by ON DELETE CASCADE.
+func (s *SQLiteDB) PurgeDeletedGoals(deletedBefore time.Time) (int, error) {
+	res, err := s.db.Exec(`DELETE FROM goals WHERE deleted_at IS NOT NULL AND deleted_at < ?`, formatTime(deletedBefore))
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// contributionColumns is the column list used by every contribution SELECT, in scanContribution order.
+const contributionColumns = `id, goal_id, user_id, kind, currency, amount_minor, note, reason, source, created_at`
+
+// scanContribution reads one contributions row into a models.Contribution.
+func scanContribution(row rowScanner) (*models.Contribution, error) {
+	var (
+		contribution models.Contribution
+		currency     string
+		amountMinor  int64
+		createdAt    string
+	)
+	err := row.Scan(
+		&contribution.ID, &contribution.GoalID, &contribution.UserID, &contribution.Kind, &currency, &amountMinor,
+		&contribution.Note, &contribution.Reason, &contribution.Source, &createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	contribution.Amount = models.NewMoney(amountMinor, currency)
+
+	if contribution.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &contribution, nil
+}
+
+// AddContribution inserts the ledger entry and updates the goal's progress in
+// one transaction, so the ledger and current_amount can never disagree.
+// If check returns an error the transaction is rolled back and the error returned.
+func (s *SQLiteDB) AddContribution(contribution *models.Contribution, check func(goal *models.Goal) error) (*models.Goal, error) {
+	var updated *models.Goal
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		goal, err := getGoal(tx, contribution.GoalID)
+		if err != nil {
+			return err
+		}
+		current := goal.Version
+		if err := check(goal); err != nil {
+			return err
+		}
+		goal.ID = contribution.GoalID
+		goal.Version = current
+		if err := goal.AddProgress(contribution.Amount, contribution.CreatedAt); err != nil {
+			return err
+		}
+		if err := updateGoal(tx, goal); err != nil {
+			return err
+		}
+		if err := insertContribution(tx, contribution, goal.Currency()); err != nil {
+			return err
+		}
+		updated = goal
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// insertContribution adds contribution to the ledger, in the goal's currency.
+func insertContribution(q querier, contribution *models.Contribution, currency string) error {
+	_, err := q.Exec(
+		`INSERT INTO contributions (`+contributionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		contribution.ID, contribution.GoalID, contribution.UserID, contribution.Kind,
+		currency, contribution.Amount.Minor,
+		contribution.Note, contribution.Reason, contribution.Source, formatTime(contribution.CreatedAt),
+	)
+	return err
+}
+
+// AddContributions records contributions to several goals in one transaction,
+// with each goal written by compare-and-swap on the version it was read at.
+func (s *SQLiteDB) AddContributions(contributions []*models.Contribution, check func(goals []*models.Goal) error) ([]*models.Goal, error) {
+	var updated []*models.Goal
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		goals := make([]*models.Goal, len(contributions))
+		versions := make([]int64, len(contributions))
+		for i, contribution := range contributions {
+			goal, err := getGoal(tx, contribution.GoalID)
+			if err != nil {
+				return err
+			}
+			goals[i], versions[i] = goal, goal.Version
+		}
+		if err := check(goals); err != nil {
+			return err
+		}
+
+		for i, contribution := range contributions {
+			if contribution.Amount.IsZero() {
+				// Nothing to record; hand back the goal as it is stored
+				goal, err := getGoal(tx, contribution.GoalID)
+				if err != nil {
+					return err
+				}
+				goals[i] = goal
+				continue
+			}
+
+			goal := goals[i]
+			goal.ID = contribution.GoalID
+			goal.Version = versions[i]
+			if err := goal.AddProgress(contribution.Amount, contribution.CreatedAt); err != nil {
+				return err
+			}
+			if err := updateGoal(tx, goal); err != nil {
+				return err
+			}
+			if err := insertContribution(tx, contribution, goal.Currency()); err != nil {
+				return err
+			}
+		}
+		updated = goals
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ListContributions returns a page of a goal's ledger, newest first,
+// and the total number of contributions for the goal.
+func (s *SQLiteDB) ListContributions(goalID string, limit, offset int) ([]*models.Contribution, int, error) {
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM contributions WHERE goal_id = ?`, goalID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// rowid breaks ties between contributions recorded at the same instant
+	rows, err := s.db.Query(
+		`SELECT `+contributionColumns+` FROM contributions WHERE goal_id = ?
+		ORDER BY created_at DESC, rowid DESC LIMIT ? OFFSET ?`,
+		goalID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	contributions := []*models.Contribution{}
+	for rows.Next() {
+		contribution, err := scanContribution(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		contributions = append(contributions, contribution)
+	}
+	return contributions, total, rows.Err()
+}
+
+// ContributionTotals sums the goal's ledger per user in SQL.
+func (s *SQLiteDB) ContributionTotals(goalID string) (map[string]models.Money, error) {
+	rows, err := s.db.Query(
+		`SELECT user_id, currency, SUM(amount_minor) FROM contributions WHERE goal_id = ? GROUP BY user_id, currency`,
+		goalID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := map[string]models.Money{}
+	for rows.Next() {
+		var (
+			userID, currency string
+			minor            int64
+		)
+		if err := rows.Scan(&userID, &currency, &minor); err != nil {
+			return nil, err
+		}
+		if _, exists := totals[userID]; exists {
+			return nil, models.ErrCurrencyMismatch
+		}
+		totals[userID] = models.NewMoney(minor, currency)
+	}
+	return totals, rows.Err()
+}
+
+// ContributionHistory returns the goal's whole ledger, oldest first.
+func (s *SQLiteDB) ContributionHistory(goalID string) ([]*models.Contribution, error) {
+	rows, err := s.db.Query(
+		`SELECT `+contributionColumns+` FROM contributions WHERE goal_id = ? ORDER BY created_at, rowid`,
+		goalID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []*models.Contribution{}
+	for rows.Next() {
+		contribution, err := scanContribution(rows)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, contribution)
+	}
+	return history, rows.Err()
+}
+
+// requireAffected returns notFound if the statement didn't touch any row.
+// UPDATE and DELETE don't fail on a missing row, so we check the count ourselves.
+func requireAffected(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// ListRolloverDue returns the active recurring goals whose period ended by now.
+func (s *SQLiteDB) ListRolloverDue(now time.Time) ([]*models.Goal, error) {
+	rows, err := s.db.Query(
+		`SELECT `+goalColumns+` FROM goals
+		WHERE recurring = 1 AND status = ? AND deleted_at IS NULL AND end_date <= ?`,
+		models.GoalActive, formatTime(now),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []*models.Goal
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		due = append(due, goal)
+	}
+	return due, rows.Err()
+}
+
+// periodColumns is the column list used by every goal_periods SELECT, in scanPeriod order.
+const periodColumns = `goal_id, number, user_id, currency, target_minor, final_minor,
+	start_date, end_date, succeeded, completed_at, archived_at, milestones`
+
+// scanPeriod reads one goal_periods row into a models.GoalPeriod.
+func scanPeriod(row rowScanner) (*models.GoalPeriod, error) {
+	var (
+		period                         models.GoalPeriod
+		currency                       string
+		targetMinor, finalMinor        int64
+		startDate, endDate, archivedAt string
+		completedAt                    sql.NullString
+		milestones                     string
+	)
+	err := row.Scan(
+		&period.GoalID, &period.Number, &period.UserID, &currency, &targetMinor, &finalMinor,
+		&startDate, &endDate, &period.Succeeded, &completedAt, &archivedAt, &milestones,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(milestones), &period.Milestones); err != nil {
+		return nil, err
+	}
+
+	period.TargetAmount = models.NewMoney(targetMinor, currency)
+	period.FinalAmount = models.NewMoney(finalMinor, currency)
+
+	if period.StartDate, err = parseTime(startDate); err != nil {
+		return nil, err
+	}
+	if period.EndDate, err = parseTime(endDate); err != nil {
+		return nil, err
+	}
+	if period.ArchivedAt, err = parseTime(archivedAt); err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		t, err := parseTime(completedAt.String)
+		if err != nil {
+			return nil, err
+		}
+		period.CompletedAt = &t
+	}
+	return &period, nil
+}
+
+// RollOverGoal archives the goal's ended periods and saves its new period
+// in one transaction, so a period is never archived twice or lost.
+func (s *SQLiteDB) RollOverGoal(id string, now time.Time, loc *time.Location) (*models.Goal, []*models.GoalPeriod, error) {
+	var (
+		updated *models.Goal
+		periods []*models.GoalPeriod
+	)
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		goal, err := getGoal(tx, id)
+		if err != nil {
+			return err
+		}
+		updated = goal
+		if periods = goal.RollOver(now, loc); len(periods) == 0 {
+			return nil
+		}
+		if err := updateGoal(tx, goal); err != nil {
+			return err
+		}
+
+		for _, period := range periods {
+			_, err := tx.Exec(
+				`INSERT INTO goal_periods (`+periodColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				period.GoalID, period.Number, period.UserID, goal.Currency(),
+				period.TargetAmount.Minor, period.FinalAmount.Minor,
+				formatTime(period.StartDate), formatTime(period.EndDate), period.Succeeded,
+				nullableTime(period.CompletedAt), formatTime(period.ArchivedAt), milestonesJSON(period.Milestones),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, periods, nil
+}
+
+// ListGoalPeriods returns a page of a goal's archived periods, newest first,
+// and the total number of periods for the goal.
+func (s *SQLiteDB) ListGoalPeriods(goalID string, limit, offset int) ([]*models.GoalPeriod, int, error) {
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM goal_periods WHERE goal_id = ?`, goalID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT `+periodColumns+` FROM goal_periods WHERE goal_id = ? ORDER BY number DESC LIMIT ? OFFSET ?`,
+		goalID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	periods := []*models.GoalPeriod{}
+	for rows.Next() {
+		period, err := scanPeriod(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		periods = append(periods, period)
+	}
+	return periods, total, rows.Err()
+}
+
+// categoryColumns is the column list used by every category SELECT, in scanCategory order.
+const categoryColumns = `id, user_id, name, color, created_at, updated_at`
+
+// scanCategory reads one categories row into a models.Category.
+func scanCategory(row rowScanner) (*models.Category, error) {
+	var (
+		category             models.Category
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&category.ID, &category.UserID, &category.Name, &category.Color, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if category.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if category.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// CreateCategory inserts a new category.
+// The unique index on (user_id, name COLLATE NOCASE) makes a duplicate name
+// insert zero rows, which is reported as ErrCategoryExists.
+func (s *SQLiteDB) CreateCategory(category *models.Category) error {
+	res, err := s.db.Exec(
+		`INSERT INTO categories (`+categoryColumns+`) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`,
+		category.ID, category.UserID, category.Name, category.Color,
+		formatTime(category.CreatedAt), formatTime(category.UpdatedAt),
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res, ErrCategoryExists)
+}
+
+// GetCategoryByID retrieves a category by ID.
+// It returns ErrCategoryNotFound if no category has that ID.
+func (s *SQLiteDB) GetCategoryByID(id string) (*models.Category, error) {
+	row := s.db.QueryRow(`SELECT `+categoryColumns+` FROM categories WHERE id = ?`, id)
+	category, err := scanCategory(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCategoryNotFound
+	}
+	return category, err
+}
+
+// ListCategories returns the user's categories sorted by name (ignoring case).
+func (s *SQLiteDB) ListCategories(userID string) ([]*models.Category, error) {
+	rows, err := s.db.Query(
+		`SELECT `+categoryColumns+` FROM categories WHERE user_id = ? ORDER BY name COLLATE NOCASE, id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []*models.Category{}
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// UpdateCategory saves the name, color and updated_at of a category.
+// The name is checked inside the transaction, so a taken name is reported as
+// ErrCategoryExists instead of a driver-specific constraint error.
+func (s *SQLiteDB) UpdateCategory(category *models.Category) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		var userID string
+		err := tx.QueryRow(`SELECT user_id FROM categories WHERE id = ?`, category.ID).Scan(&userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCategoryNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var taken bool
+		err = tx.QueryRow(
+			`SELECT EXISTS(SELECT 1 FROM categories WHERE user_id = ? AND name = ? COLLATE NOCASE AND id <> ?)`,
+			userID, category.Name, category.ID,
+		).Scan(&taken)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrCategoryExists
+		}
+
+		_, err = tx.Exec(
+			`UPDATE categories SET name = ?, color = ?, updated_at = ? WHERE id = ?`,
+			category.Name, category.Color, formatTime(category.UpdatedAt), category.ID,
+		)
+		return err
+	})
+}
+
+// DeleteCategory removes a category and, in the same transaction, takes its
+// goals out of it (bumping their version, since they changed).
+// It returns ErrCategoryNotFound if no category has that ID.
+func (s *SQLiteDB) DeleteCategory(id string) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		res, err := tx.Exec(`DELETE FROM categories WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(res, ErrCategoryNotFound); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE goals SET category_id = NULL, version = version + 1 WHERE category_id = ?`, id)
+		return err
+	})
+}
+
+// memberColumns is the column list used by every goal_members SELECT, in scanMember order.
+const memberColumns = `goal_id, user_id, role, joined_at`
+
+// scanMember reads one goal_members row into a models.GoalMember.
+func scanMember(row rowScanner) (*models.GoalMember, error) {
+	var (
+		member   models.GoalMember
+		joinedAt string
+	)
+	if err := row.Scan(&member.GoalID, &member.UserID, &member.Role, &joinedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if member.JoinedAt, err = parseTime(joinedAt); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListGoalMembers returns the goal's members in the order they joined.
+func (s *SQLiteDB) ListGoalMembers(goalID string) ([]*models.GoalMember, error) {
+	rows, err := s.db.Query(`SELECT `+memberColumns+` FROM goal_members WHERE goal_id = ? ORDER BY joined_at, user_id`, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*models.GoalMember{}
+	for rows.Next() {
+		member, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// GetGoalMember returns userID's membership of goalID, or ErrMemberNotFound.
+func (s *SQLiteDB) GetGoalMember(goalID, userID string) (*models.GoalMember, error) {
+	row := s.db.QueryRow(`SELECT `+memberColumns+` FROM goal_members WHERE goal_id = ? AND user_id = ?`, goalID, userID)
+	member, err := scanMember(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMemberNotFound
+	}
+	return member, err
+}
+
+// UpdateGoalMember saves the role of an existing member.
+// It returns ErrMemberNotFound if the user isn't a member of the goal.
+func (s *SQLiteDB) UpdateGoalMember(member *models.GoalMember) error {
+	res, err := s.db.Exec(
+		`UPDATE goal_members SET role = ? WHERE goal_id = ? AND user_id = ?`,
+		member.Role, member.GoalID, member.UserID,
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res, ErrMemberNotFound)
+}
+
+// RemoveGoalMember stops sharing goalID with userID.
+// It returns ErrMemberNotFound if the user isn't a member of the goal.
+func (s *SQLiteDB) RemoveGoalMember(goalID, userID string) error {
+	res, err := s.db.Exec(`DELETE FROM goal_members WHERE goal_id = ? AND user_id = ?`, goalID, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res, ErrMemberNotFound)
+}
+
+// invitationColumns is the column list used by every goal_invitations SELECT, in scanInvitation order.
+const invitationColumns = `id, goal_id, email, role, invited_by, status, created_at, responded_at`
+
+// scanInvitation reads one goal_invitations row into a models.Invitation.
+func scanInvitation(row rowScanner) (*models.Invitation, error) {
+	var (
+		invitation  models.Invitation
+		createdAt   string
+		respondedAt sql.NullString
+	)
+	err := row.Scan(
+		&invitation.ID, &invitation.GoalID, &invitation.Email, &invitation.Role,
+		&invitation.InvitedBy, &invitation.Status, &createdAt, &respondedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if respondedAt.Valid {
+		t, err := parseTime(respondedAt.String)
+		if err != nil {
+			return nil, err
+		}
+		invitation.RespondedAt = &t
+	}
+	return &invitation, nil
+}
+
+// CreateInvitation inserts a pending invitation.
+// The partial unique index on pending (goal_id, email) makes a second pending
+// invitation insert zero rows, which is reported as ErrInvitationExists.
+func (s *SQLiteDB) CreateInvitation(invitation *models.Invitation) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM goals WHERE id = ?)`, invitation.GoalID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrGoalNotFound
+		}
+
+		res, err := tx.Exec(
+			`INSERT INTO goal_invitations (`+invitationColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`,
+			invitation.ID, invitation.GoalID, invitation.Email, invitation.Role, invitation.InvitedBy,
+			invitation.Status, formatTime(invitation.CreatedAt), nullableTime(invitation.RespondedAt),
+		)
+		if err != nil {
+			return err
+		}
+		return requireAffected(res, ErrInvitationExists)
+	})
+}
+
+// GetInvitation returns the invitation with the given ID, or ErrInvitationNotFound.
+func (s *SQLiteDB) GetInvitation(id string) (*models.Invitation, error) {
+	row := s.db.QueryRow(`SELECT `+invitationColumns+` FROM goal_invitations WHERE id = ?`, id)
+	invitation, err := scanInvitation(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvitationNotFound
+	}
+	return invitation, err
+}
+
+// ListGoalInvitations returns the goal's pending invitations, oldest first.
+func (s *SQLiteDB) ListGoalInvitations(goalID string) ([]*models.Invitation, error) {
+	return s.listInvitations(`goal_id = ?`, goalID)
+}
+
+// ListInvitationsByEmail returns the pending invitations sent to email, oldest first.
+func (s *SQLiteDB) ListInvitationsByEmail(email string) ([]*models.Invitation, error) {
+	return s.listInvitations(`email = ?`, email)
+}
+
+// listInvitations returns the pending invitations matching the fixed
+// condition cond (with its one argument), oldest first.
+func (s *SQLiteDB) listInvitations(cond string, arg interface{}) ([]*models.Invitation, error) {
+	rows, err := s.db.Query(
+		`SELECT `+invitationColumns+` FROM goal_invitations WHERE `+cond+` AND status = ? ORDER BY created_at, id`,
+		arg, models.InvitationPending,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []*models.Invitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+	return invitations, rows.Err()
+}
+
+// RespondToInvitation answers a pending invitation and, if accepted, adds the
+// member, in one transaction. Answering an invitation whose goal was deleted
+// can't happen: the invitation was deleted with it (ErrInvitationNotFound).
+func (s *SQLiteDB) RespondToInvitation(id, userID string, accept bool, at time.Time) (*models.Invitation, error) {
+	var answered *models.Invitation
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		invitation, err := scanInvitation(tx.QueryRow(`SELECT `+invitationColumns+` FROM goal_invitations WHERE id = ?`, id))
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvitationNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if invitation.Status != models.InvitationPending {
+			return ErrInvitationClosed
+		}
+		if accept {
+			var ownerID string
+			err := tx.QueryRow(`SELECT user_id FROM goals WHERE id = ?`, invitation.GoalID).Scan(&ownerID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrGoalNotFound
+			}
+			if err != nil {
+				return err
+			}
+			if ownerID == userID {
+				return ErrInviteeIsOwner
+			}
+		}
+
+		invitation.Status = models.InvitationDeclined
+		invitation.RespondedAt = &at
+		if accept {
+			invitation.Status = models.InvitationAccepted
+			_, err := tx.Exec(
+				`INSERT INTO goal_members (`+memberColumns+`) VALUES (?, ?, ?, ?)
+				ON CONFLICT (goal_id, user_id) DO UPDATE SET role = excluded.role`,
+				invitation.GoalID, userID, invitation.Role, formatTime(at),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Exec(
+			`UPDATE goal_invitations SET status = ?, responded_at = ? WHERE id = ?`,
+			invitation.Status, formatTime(at), id,
+		)
+		answered = invitation
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return answered, nil
+}
+
+// DeleteInvitation removes an invitation, or returns ErrInvitationNotFound.
+func (s *SQLiteDB) DeleteInvitation(id string) error {
+	res, err := s.db.Exec(`DELETE FROM goal_invitations WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res, ErrInvitationNotFound)
+}
+
+// templateColumns is the column list used by every goal_templates SELECT, in scanTemplate order.
+const templateColumns = `id, user_id, name, description, title, currency, target_minor, duration, recurring,
+	milestones, tags, created_at`
+
+// scanTemplate reads one goal_templates row into a models.GoalTemplate.
+func scanTemplate(row rowScanner) (*models.GoalTemplate, error) {
+	var (
+		template         models.GoalTemplate
+		userID           sql.NullString
+		targetMinor      int64
+		milestones, tags string
+		createdAt        string
+	)
+	err := row.Scan(
+		&template.ID, &userID, &template.Name, &template.Description, &template.Title, &template.Currency,
+		&targetMinor, &template.Duration, &template.Recurring, &milestones, &tags, &createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	template.UserID = userID.String
+	template.TargetAmount = models.NewMoney(targetMinor, template.Currency)
+	if err := json.Unmarshal([]byte(milestones), &template.Milestones); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tags), &template.Tags); err != nil {
+		return nil, err
+	}
+	if template.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// templateMilestonesJSON encodes a template's milestones for their TEXT column.
+func templateMilestonesJSON(milestones []models.MilestoneRequest) string {
+	if len(milestones) == 0 {
+		return "[]"
+	}
+	data, _ := json.Marshal(milestones)
+	return string(data)
+}
+
+// SeedTemplates upserts the built-in templates and deletes the built-in ones
+// that aren't listed, in one transaction. Updates keep created_at, and never
+// touch a private template that happens to have the same ID.
+func (s *SQLiteDB) SeedTemplates(templates []*models.GoalTemplate) error {
+	return withTx(s.db, func(tx *sql.Tx) error {
+		ids := make([]interface{}, 0, len(templates))
+		for _, template := range templates {
+			_, err := tx.Exec(
+				`INSERT INTO goal_templates (`+templateColumns+`) VALUES (?, NULL, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (id) DO UPDATE SET
+					name = excluded.name, description = excluded.description, title = excluded.title,
+					currency = excluded.currency, target_minor = excluded.target_minor,
+					duration = excluded.duration, recurring = excluded.recurring,
+					milestones = excluded.milestones, tags = excluded.tags
+				WHERE goal_templates.user_id IS NULL`,
+				template.ID, template.Name, template.Description, template.Title, template.Currency,
+				template.TargetAmount.Minor, template.Duration, template.Recurring,
+				templateMilestonesJSON(template.Milestones), tagsJSON(template.Tags), formatTime(template.CreatedAt),
+			)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, template.ID)
+		}
+
+		query := `DELETE FROM goal_templates WHERE user_id IS NULL`
+		if len(ids) > 0 {
+			query += ` AND id NOT IN (?` + strings.Repeat(", ?", len(ids)-1) + `)`
+		}
+		_, err := tx.Exec(query, ids...)
+		return err
+	})
+}
+
+// CreateTemplate inserts a user's private template.
+// It returns ErrTemplateExists if a template with the same ID already exists.
+func (s *SQLiteDB) CreateTemplate(template *models.GoalTemplate) error {
+	res, err := s.db.Exec(
+		`INSERT INTO goal_templates (`+templateColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`,
+		template.ID, nullableString(template.UserID), template.Name, template.Description, template.Title,
+		template.Currency, template.TargetAmount.Minor, template.Duration, template.Recurring,
+		templateMilestonesJSON(template.Milestones), tagsJSON(template.Tags), formatTime(template.CreatedAt),
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res, ErrTemplateExists)
+}
+
+// GetTemplate returns the template with the given ID, or ErrTemplateNotFound.
+func (s *SQLiteDB) GetTemplate(id string) (*models.GoalTemplate, error) {
+	row := s.db.QueryRow(`SELECT `+templateColumns+` FROM goal_templates WHERE id = ?`, id)
+	template, err := scanTemplate(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTemplateNotFound
+	}
+	return template, err
+}
+
+// ListTemplates returns the built-in templates followed by the user's own, each sorted by name.
+func (s *SQLiteDB) ListTemplates(userID string) ([]*models.GoalTemplate, error) {
+	rows, err := s.db.Query(
+		`SELECT `+templateColumns+` FROM goal_templates WHERE user_id IS NULL OR user_id = ?
+		ORDER BY user_id IS NOT NULL, name COLLATE NOCASE, id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []*models.GoalTemplate{}
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, rows.Err()
+}
+
+// DeleteTemplate removes a template, or returns ErrTemplateNotFound.
+func (s *SQLiteDB) DeleteTemplate(id string) error {
+	res, err := s.db.Exec(`DELETE FROM goal_templates WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res, ErrTemplateNotFound)
+}
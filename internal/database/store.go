@@ -0,0 +1,185 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"go-api-server/internal/models"
+)
+
+// Errors returned by every Store implementation.
+// Handlers compare against these with errors.Is so they can pick the right
+// HTTP status code without knowing which backend is in use.
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists   = errors.New("user with this email already exists")
+	ErrGoalNotFound = errors.New("goal not found")
+	ErrGoalExists   = errors.New("goal with this ID already exists")
+
+	ErrCategoryNotFound = errors.New("category not found")
+	// ErrCategoryExists means the user already has a category with that name.
+	ErrCategoryExists = errors.New("category with this name already exists")
+
+	ErrMemberNotFound     = errors.New("user is not a member of this goal")
+	ErrInvitationNotFound = errors.New("invitation not found")
+	// ErrInvitationExists means the email already has a pending invitation to the goal.
+	ErrInvitationExists = errors.New("a pending invitation for this email already exists")
+	// ErrInvitationClosed means the invitation was already accepted or declined.
+	ErrInvitationClosed = errors.New("invitation is no longer pending")
+	// ErrInviteeIsOwner means the goal's owner tried to accept an invitation
+	// to their own goal.
+	ErrInviteeIsOwner = errors.New("the goal's owner can't join it as a member")
+
+	ErrTemplateNotFound = errors.New("template not found")
+	ErrTemplateExists   = errors.New("template with this ID already exists")
+
+	// ErrVersionConflict means the goal was changed by someone else since
+	// the caller read it (its Version no longer matches the stored one).
+	ErrVersionConflict = errors.New("goal was modified concurrently")
+)
+
+// Store is the storage interface used by the HTTP handlers.
+// InMemoryDB is one implementation; persistent backends implement the same
+// methods so they can be swapped in from main.go without touching handler code.
+//
+// Values returned by a Store are copies owned by the caller: modifying them
+// has no effect until they are written back with UpdateGoal/UpdateGoalFunc.
+type Store interface {
+	// Users
+	CreateUser(user *models.User) error
+	GetUserByEmail(email string) (*models.User, error)
+	GetUserByID(id string) (*models.User, error)
+	// UpdateUser saves the settings of the user with user.ID (the email and
+	// streaks can't change).
+	UpdateUser(user *models.User) error
+	// UpdateUserStreaks atomically passes the streaks of user id to fn and
+	// saves the result. If fn returns an error nothing is saved and the error
+	// is returned unchanged.
+	UpdateUserStreaks(id string, fn func(streaks *models.Streaks) error) (*models.Streaks, error)
+	DeleteUser(email string) error
+	GetAllUsers() ([]*models.User, error)
+
+	// Goals
+	// CreateGoal stores a new goal; its Version starts at 1 and its Status
+	// defaults to active.
+	CreateGoal(goal *models.Goal) error
+	GetGoalsByUserID(userID string) ([]*models.Goal, error)
+	// ListGoals returns one page of the goals query.UserID owns or is a member
+	// of that pass the query's filters, in the query's sort order with ties broken by ID. NextCursor is
+	// set when more goals follow; an unusable query.Cursor is ErrInvalidCursor.
+	ListGoals(query models.GoalQuery) (*models.GoalPage, error)
+	GetGoalByID(id string) (*models.Goal, error)
+	// UpdateGoal is a compare-and-swap: it only saves if goal.Version still
+	// matches the stored version (ErrVersionConflict otherwise). On success
+	// the version is incremented and goal.Version is updated to the new value.
+	UpdateGoal(goal *models.Goal) error
+
+	// UpdateGoalFunc atomically loads the goal, passes a copy to fn and saves
+	// the result with the next version. If fn returns an error nothing is saved
+	// and the error is returned unchanged, so callers can abort with their own
+	// sentinel errors.
+	UpdateGoalFunc(id string, fn func(goal *models.Goal) error) (*models.Goal, error)
+	// DeleteGoal removes the goal together with its contributions, periods,
+	// members and invitations. Deleting a goal through the API only sets its
+	// DeletedAt (see Goal.Delete); this is what purging it comes down to.
+	DeleteGoal(id string) error
+	// PurgeDeletedGoals removes every goal deleted before deletedBefore, like
+	// DeleteGoal, and returns how many were removed.
+	PurgeDeletedGoals(deletedBefore time.Time) (int, error)
+
+	// Contributions
+	// AddContribution atomically appends contribution to the ledger of goal
+	// contribution.GoalID and adds its amount (negative for withdrawals) to the
+	// goal's progress, reopening or completing it as needed (see
+	// Goal.AddProgress) and saving the goal with the next version.
+	// check runs first on a copy of the goal; if it returns an error nothing is
+	// saved and the error is returned unchanged. The contribution is read after
+	// check returns, so check may still fill in its Amount (e.g. once the
+	// goal's currency is known); changes check makes to the goal (e.g. to its
+	// streaks) are saved with it.
+	AddContribution(contribution *models.Contribution, check func(goal *models.Goal) error) (*models.Goal, error)
+	// AddContributions is AddContribution for several goals at once (e.g. to
+	// split one deposit across them): either every goal is updated or none.
+	// check runs first on copies of the goals of contributions, in the same
+	// order, and may fill in the contributions' Amounts. Contributions whose
+	// Amount is zero are skipped, leaving their goal unchanged. It returns
+	// the goals in the same order, as saved.
+	AddContributions(contributions []*models.Contribution, check func(goals []*models.Goal) error) ([]*models.Goal, error)
+	// ListContributions returns up to limit of the goal's contributions, newest
+	// first, skipping the first offset, plus the total number of contributions.
+	ListContributions(goalID string, limit, offset int) ([]*models.Contribution, int, error)
+	// ContributionTotals sums the goal's ledger per user ID, over all periods.
+	ContributionTotals(goalID string) (map[string]models.Money, error)
+	// ContributionHistory returns the goal's whole ledger, over all periods,
+	// oldest first (e.g. for analytics).
+	ContributionHistory(goalID string) ([]*models.Contribution, error)
+
+	// Recurring goals
+	// ListRolloverDue returns copies of the active recurring goals whose
+	// period ended by now (see Goal.RolloverDue).
+	ListRolloverDue(now time.Time) ([]*models.Goal, error)
+	// RollOverGoal atomically archives the goal's ended periods and starts the
+	// next one (see Goal.RollOver), with period boundaries in loc, and saves
+	// the goal with the next version. If the goal is no longer due (e.g. it
+	// was rolled over already) nothing changes and the returned periods are empty.
+	RollOverGoal(id string, now time.Time, loc *time.Location) (*models.Goal, []*models.GoalPeriod, error)
+	// ListGoalPeriods returns up to limit of the goal's archived periods,
+	// newest first, skipping the first offset, plus the total number of periods.
+	ListGoalPeriods(goalID string, limit, offset int) ([]*models.GoalPeriod, int, error)
+
+	// Categories
+	// CreateCategory stores a new category; names are unique per user
+	// ignoring case (ErrCategoryExists).
+	CreateCategory(category *models.Category) error
+	GetCategoryByID(id string) (*models.Category, error)
+	// ListCategories returns the user's categories sorted by name.
+	ListCategories(userID string) ([]*models.Category, error)
+	// UpdateCategory saves the name and color of the category with category.ID.
+	UpdateCategory(category *models.Category) error
+	// DeleteCategory removes the category; its goals are left without a
+	// category (and saved with the next version).
+	DeleteCategory(id string) error
+
+	// Shared goals
+	// ListGoalMembers returns the goal's members (not its owner), in the order they joined.
+	ListGoalMembers(goalID string) ([]*models.GoalMember, error)
+	GetGoalMember(goalID, userID string) (*models.GoalMember, error)
+	// UpdateGoalMember saves the role of an existing member.
+	UpdateGoalMember(member *models.GoalMember) error
+	RemoveGoalMember(goalID, userID string) error
+
+	// Invitations
+	// CreateInvitation stores a pending invitation. Each email can have only
+	// one pending invitation per goal (ErrInvitationExists).
+	CreateInvitation(invitation *models.Invitation) error
+	GetInvitation(id string) (*models.Invitation, error)
+	// ListGoalInvitations returns the goal's pending invitations, oldest first.
+	ListGoalInvitations(goalID string) ([]*models.Invitation, error)
+	// ListInvitationsByEmail returns the pending invitations sent to email
+	// (already normalized), oldest first.
+	ListInvitationsByEmail(email string) ([]*models.Invitation, error)
+	// RespondToInvitation atomically accepts or declines a pending invitation
+	// (ErrInvitationClosed otherwise) at the given time. Accepting makes
+	// userID a member of the goal with the invitation's role; the goal's
+	// owner can't accept (ErrInviteeIsOwner).
+	RespondToInvitation(id, userID string, accept bool, at time.Time) (*models.Invitation, error)
+	// DeleteInvitation removes an invitation, e.g. when the owner revokes it.
+	DeleteInvitation(id string) error
+
+	// Goal templates
+	// SeedTemplates makes the built-in templates (those without a UserID)
+	// match templates: new ones are added, changed ones updated (keeping
+	// their CreatedAt) and built-in templates that aren't listed removed.
+	// Unchanged templates are left alone, so seeding on every start is cheap.
+	SeedTemplates(templates []*models.GoalTemplate) error
+	// CreateTemplate stores a user's private template.
+	CreateTemplate(template *models.GoalTemplate) error
+	GetTemplate(id string) (*models.GoalTemplate, error)
+	// ListTemplates returns the built-in templates followed by userID's own,
+	// each sorted by name.
+	ListTemplates(userID string) ([]*models.GoalTemplate, error)
+	DeleteTemplate(id string) error
+}
+
+// Compile-time check that InMemoryDB satisfies the Store interface.
+var _ Store = (*InMemoryDB)(nil)
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"database/sql"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-api-server/internal/models"
+)
+
+// openTestSQLite opens a migrated SQLite store in a temporary directory.
+// The test is skipped if the binary was built without the SQLite driver.
+func openTestSQLite(t *testing.T) *SQLiteDB {
+	t.Helper()
+	registered := false
+	for _, driver := range sql.Drivers() {
+		registered = registered || driver == "sqlite"
+	}
+	if !registered {
+		t.Skip("sqlite driver not registered")
+	}
+	db, err := NewSQLiteDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrator, err := db.Migrator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// TestListGoalsProgressOrderAcrossStores sorts goals with balances too large
+// to multiply by 10000 by progress, one goal per page, in both stores: they
+// must return the same order.
+func TestListGoalsProgressOrderAcrossStores(t *testing.T) {
+	amounts := []struct {
+		id              string
+		current, target int64
+	}{
+		{"half", 50_00, 100_00},
+		{"done", 100_00, 100_00},
+		{"huge-small-target", math.MaxInt64 / 2, 100_00},
+		{"huge-tiny-target", math.MaxInt64, 1},
+		{"huge-huge-target", math.MaxInt64 - 1, math.MaxInt64 / 4},
+		{"huge-just-short", math.MaxInt64 / 2, math.MaxInt64 - 1},
+	}
+	// half and huge-just-short are both at 5000 basis points; ties go by ID
+	want := []string{"half", "huge-just-short", "done", "huge-huge-target", "huge-small-target", "huge-tiny-target"}
+
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store { return NewInMemoryDB() },
+		"sqlite": func(t *testing.T) Store { return openTestSQLite(t) },
+	}
+	for name, open := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			now := time.Now()
+			if err := store.CreateUser(&models.User{ID: "user-1", Email: "user-1@example.com", CreatedAt: now, UpdatedAt: now}); err != nil {
+				t.Fatal(err)
+			}
+			for _, amount := range amounts {
+				goal := &models.Goal{
+					ID:            amount.id,
+					UserID:        "user-1",
+					Title:         amount.id,
+					TargetAmount:  models.NewMoney(amount.target, "USD"),
+					CurrentAmount: models.NewMoney(amount.current, "USD"),
+					Duration:      models.Monthly,
+					StartDate:     now,
+					EndDate:       models.Monthly.EndDate(now),
+					CreatedAt:     now,
+				}
+				if err := store.CreateGoal(goal); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var got []string
+			query := models.GoalQuery{UserID: "user-1", Sort: models.GoalSortProgress, Limit: 1}
+			for {
+				page, err := store.ListGoals(query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, goal := range page.Goals {
+					got = append(got, goal.ID)
+				}
+				if page.NextCursor == "" || len(got) > len(amounts) {
+					break
+				}
+				query.Cursor = page.NextCursor
+			}
+			if len(got) != len(want) {
+				t.Fatalf("listed %v, want %v", got, want)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("listed %v, want %v", got, want)
+					break
+				}
+			}
+		})
+	}
+}
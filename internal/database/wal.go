@@ -0,0 +1,592 @@
+package database
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"go-api-server/internal/models"
+)
+
+// Durability for InMemoryDB
+//
+// When opened with OpenDurableInMemoryDB, every mutation is appended to a
+// write-ahead log (WAL) *before* it is applied to the maps. On startup the
+// latest snapshot is loaded and the WAL is replayed on top of it, so the
+// in-memory state survives restarts.
+//
+// To keep the WAL from growing forever, a compacted snapshot of the whole
+// database is written every SnapshotEvery records and the WAL is reset.
+//
+// Files inside the data directory:
+//   - snapshot.json: full copy of the data as of sequence number Seq
+//   - wal.log:       records written after the snapshot
+//
+// WAL record layout (all integers big-endian):
+//
+//	+----------------+----------------+------------------------+
+//	| length uint32  | crc32 uint32   | JSON payload (length)  |
+//	+----------------+----------------+------------------------+
+//
+// If the server crashes in the middle of an append, the final record is
+// incomplete. Replay detects this (short read or checksum mismatch at the end
+// of the file), truncates the torn record, and carries on.
+//
+// If an append fails while the server keeps running (e.g. the disk is full),
+// the partial record is cut off right away so the next one isn't written
+// after it. If that fails too, the WAL is unusable and every later mutation
+// fails with ErrWALUnusable until the server is restarted.
+
+// ErrWALUnusable is returned by every mutation after a failed WAL append
+// could not be rolled back.
+var ErrWALUnusable = errors.New("wal is unusable after a failed write")
+
+const (
+	walFileName      = "wal.log"
+	snapshotFileName = "snapshot.json"
+
+	// walHeaderSize is the length + checksum prefix in front of each record
+	walHeaderSize = 8
+
+	// defaultSnapshotEvery is used when DurabilityOptions.SnapshotEvery is 0
+	defaultSnapshotEvery = 1000
+)
+
+// WAL operation names. Each one corresponds to an InMemoryDB mutation.
+const (
+	opCreateUser = "create_user"
+	opUpdateUser = "update_user"
+	opDeleteUser = "delete_user"
+	opCreateGoal = "create_goal"
+	opUpdateGoal = "update_goal"
+	opDeleteGoal = "delete_goal"
+
+	// opAddContribution carries both the ledger entry and the updated goal,
+	// so the two are always replayed together
+	opAddContribution = "add_contribution"
+	// opAddContributions does the same for several goals at once
+	opAddContributions = "add_contributions"
+
+	// opRollOverGoal carries the goal's new period and the archived ones
+	opRollOverGoal = "roll_over_goal"
+
+	opCreateCategory = "create_category"
+	opUpdateCategory = "update_category"
+	// opDeleteCategory also takes the category's goals out of it on replay
+	opDeleteCategory = "delete_category"
+
+	opPutMember    = "put_member"
+	opRemoveMember = "remove_member"
+
+	opPutInvitation = "put_invitation"
+	// opRespondInvitation carries the answered invitation and, if it was
+	// accepted, the new member
+	opRespondInvitation = "respond_invitation"
+	opDeleteInvitation  = "delete_invitation"
+
+	opPutTemplate    = "put_template"
+	opDeleteTemplate = "delete_template"
+)
+
+// DurabilityOptions configures OpenDurableInMemoryDB.
+type DurabilityOptions struct {
+	// SnapshotEvery is the number of WAL records after which a compacted
+	// snapshot is written and the WAL is reset. 0 means defaultSnapshotEvery.
+	SnapshotEvery int
+
+	// NoSync skips fsync after each append. Writes are faster, but the last
+	// few mutations can be lost if the machine (not just the process) crashes.
+	NoSync bool
+}
+
+// persistedUser is how a user is written to the WAL and snapshot.
+// models.User hides Password from JSON (json:"-") so it never leaks in API
+// responses; here we need it, so the outer Password field takes precedence.
+type persistedUser struct {
+	models.User
+	Password string `json:"password"`
+}
+
+func toPersistedUser(user *models.User) persistedUser {
+	return persistedUser{User: *user, Password: user.Password}
+}
+
+func (p persistedUser) toUser() *models.User {
+	user := p.User
+	user.Password = p.Password
+	return &user
+}
+
+// walRecord is one logged mutation. Only the fields relevant to Op are set.
+type walRecord struct {
+	Seq           uint64                 `json:"seq"`
+	Op            string                 `json:"op"`
+	User          *persistedUser         `json:"user,omitempty"`
+	Goal          *models.Goal           `json:"goal,omitempty"`
+	Contribution  *models.Contribution   `json:"contribution,omitempty"`
+	Goals         []*models.Goal         `json:"goals,omitempty"`
+	Contributions []*models.Contribution `json:"contributions,omitempty"`
+	Periods       []*models.GoalPeriod   `json:"periods,omitempty"`
+	Category      *models.Category       `json:"category,omitempty"`
+	Member        *models.GoalMember     `json:"member,omitempty"`
+	Invitation    *models.Invitation     `json:"invitation,omitempty"`
+	Template      *models.GoalTemplate   `json:"template,omitempty"`
+	Email         string                 `json:"email,omitempty"`
+	ID            string                 `json:"id,omitempty"`
+}
+
+// snapshot is the full database state as of sequence number Seq.
+type snapshot struct {
+	Seq           uint64                 `json:"seq"`
+	Users         []persistedUser        `json:"users"`
+	Goals         []*models.Goal         `json:"goals"`
+	Contributions []*models.Contribution `json:"contributions,omitempty"`
+	Periods       []*models.GoalPeriod   `json:"periods,omitempty"`
+	Categories    []*models.Category     `json:"categories,omitempty"`
+	Members       []*models.GoalMember   `json:"members,omitempty"`
+	Invitations   []*models.Invitation   `json:"invitations,omitempty"`
+	Templates     []*models.GoalTemplate `json:"templates,omitempty"`
+}
+
+// walFile is the part of *os.File the WAL appends with.
+type walFile interface {
+	io.Writer
+	io.Seeker
+	Sync() error
+	Truncate(size int64) error
+	Close() error
+}
+
+// walLog is the open WAL file plus snapshot bookkeeping.
+// It is only used while holding InMemoryDB.mu for writing.
+type walLog struct {
+	dir  string
+	file walFile
+
+	// size is the length of the WAL file: where the next record starts
+	size int64
+
+	// failed is set once a failed append couldn't be rolled back; every
+	// mutation returns it from then on
+	failed error
+
+	// seq is the sequence number of the last record written
+	seq uint64
+
+	// sinceSnapshot counts records appended since the last snapshot
+	sinceSnapshot int
+	snapshotEvery int
+	sync          bool
+}
+
+// OpenDurableInMemoryDB creates an InMemoryDB whose mutations are persisted to
+// a WAL in dir. Existing data in dir (snapshot + WAL) is loaded first.
+// Parameters:
+//   - dir: directory for wal.log and snapshot.json (created if missing)
+//   - opts: snapshot frequency and fsync behaviour
+//
+// Returns:
+//   - *InMemoryDB: the database with all previously persisted data restored
+//   - error: nil if successful, error if the files can't be read or are corrupted
+func OpenDurableInMemoryDB(dir string, opts DurabilityOptions) (*InMemoryDB, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	db := NewInMemoryDB()
+
+	// 1. Load the last snapshot (if any)
+	snapSeq, err := db.loadSnapshot(filepath.Join(dir, snapshotFileName))
+	if err != nil {
+		return nil, fmt.Errorf("load snapshot: %w", err)
+	}
+
+	// 2. Replay WAL records written after the snapshot
+	file, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	lastSeq, replayed, err := db.replayWAL(file, snapSeq)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("replay wal: %w", err)
+	}
+
+	// New records are appended after the last good one
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	snapshotEvery := opts.SnapshotEvery
+	if snapshotEvery <= 0 {
+		snapshotEvery = defaultSnapshotEvery
+	}
+	db.wal = &walLog{
+		dir:           dir,
+		file:          file,
+		size:          size,
+		seq:           lastSeq,
+		sinceSnapshot: replayed,
+		snapshotEvery: snapshotEvery,
+		sync:          !opts.NoSync,
+	}
+	return db, nil
+}
+
+// Close closes the WAL file. It is a no-op for a non-durable InMemoryDB.
+func (db *InMemoryDB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.wal == nil {
+		return nil
+	}
+	err := db.wal.file.Close()
+	db.wal = nil
+	return err
+}
+
+// Snapshot writes a compacted snapshot now and resets the WAL.
+// It is called automatically every SnapshotEvery records, but can also be
+// triggered manually (e.g. before a planned shutdown).
+func (db *InMemoryDB) Snapshot() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.wal == nil {
+		return errors.New("durability is not enabled for this database")
+	}
+	return db.writeSnapshot()
+}
+
+// logMutation appends rec to the WAL. It must be called with db.mu held for
+// writing, after validating the mutation and before applying it, so that the
+// log never contains a change the maps rejected.
+// It does nothing when durability is disabled.
+func (db *InMemoryDB) logMutation(rec walRecord) error {
+	if db.wal == nil {
+		return nil
+	}
+	if db.wal.failed != nil {
+		return db.wal.failed
+	}
+
+	rec.Seq = db.wal.seq + 1
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	frame := make([]byte, walHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(payload))
+	copy(frame[walHeaderSize:], payload)
+
+	if _, err := db.wal.file.Write(frame); err != nil {
+		return db.rewindWAL(fmt.Errorf("write wal: %w", err))
+	}
+	if db.wal.sync {
+		if err := db.wal.file.Sync(); err != nil {
+			return db.rewindWAL(fmt.Errorf("sync wal: %w", err))
+		}
+	}
+
+	db.wal.size += int64(len(frame))
+	db.wal.seq = rec.Seq
+	db.wal.sinceSnapshot++
+	return nil
+}
+
+// rewindWAL undoes a failed append: whatever part of the record reached the
+// file is truncated away and the next record is written where it started.
+// Otherwise the next record would follow a torn one, and replay would refuse
+// to start on the checksum mismatch in the middle of the file.
+// If the WAL can't be rewound, it is marked unusable. It returns err.
+func (db *InMemoryDB) rewindWAL(err error) error {
+	if terr := db.wal.file.Truncate(db.wal.size); terr != nil {
+		db.wal.failed = fmt.Errorf("%w: %v (truncate: %v)", ErrWALUnusable, err, terr)
+		log.Printf("ERROR: %v", db.wal.failed)
+		return err
+	}
+	if _, serr := db.wal.file.Seek(db.wal.size, io.SeekStart); serr != nil {
+		db.wal.failed = fmt.Errorf("%w: %v (seek: %v)", ErrWALUnusable, err, serr)
+		log.Printf("ERROR: %v", db.wal.failed)
+	}
+	return err
+}
+
+// maybeSnapshot compacts the WAL once enough records have accumulated.
+// It is called after a mutation has been applied. A failed snapshot is only
+// logged: the mutation itself is already safely in the WAL.
+func (db *InMemoryDB) maybeSnapshot() {
+	if db.wal == nil || db.wal.sinceSnapshot < db.wal.snapshotEvery {
+		return
+	}
+	if err := db.writeSnapshot(); err != nil {
+		log.Printf("WARNING: snapshot failed, WAL keeps growing: %v", err)
+	}
+}
+
+// writeSnapshot saves the full state atomically (write temp file, fsync,
+// rename) and then truncates the WAL. Must be called with db.mu held.
+//
+// If we crash after the rename but before the truncate, the WAL still holds
+// records the snapshot already contains; replay skips them by sequence number.
+func (db *InMemoryDB) writeSnapshot() error {
+	snap := snapshot{
+		Seq:   db.wal.seq,
+		Users: make([]persistedUser, 0, len(db.users)),
+		Goals: make([]*models.Goal, 0, len(db.goals)),
+	}
+	for _, user := range db.users {
+		snap.Users = append(snap.Users, toPersistedUser(user))
+	}
+	for _, goal := range db.goals {
+		snap.Goals = append(snap.Goals, goal)
+	}
+	// Each goal's ledger is written oldest first so loading preserves the order
+	for _, ledger := range db.contributions {
+		snap.Contributions = append(snap.Contributions, ledger...)
+	}
+	for _, archive := range db.periods {
+		snap.Periods = append(snap.Periods, archive...)
+	}
+	for _, category := range db.categories {
+		snap.Categories = append(snap.Categories, category)
+	}
+	for _, members := range db.members {
+		for _, member := range members {
+			snap.Members = append(snap.Members, member)
+		}
+	}
+	for _, invitation := range db.invitations {
+		snap.Invitations = append(snap.Invitations, invitation)
+	}
+	for _, template := range db.templates {
+		snap.Templates = append(snap.Templates, template)
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(db.wal.dir, snapshotFileName)
+	tmp := path + ".tmp"
+	if err := writeFileSync(tmp, data); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	syncDir(db.wal.dir)
+
+	// Everything in the WAL is now part of the snapshot
+	if err := db.wal.file.Truncate(0); err != nil {
+		return err
+	}
+	db.wal.size = 0
+	if _, err := db.wal.file.Seek(0, io.SeekStart); err != nil {
+		db.wal.failed = fmt.Errorf("%w: seek: %v", ErrWALUnusable, err)
+		return err
+	}
+	db.wal.sinceSnapshot = 0
+	return nil
+}
+
+// loadSnapshot restores the state from the snapshot file, if it exists.
+// Returns the snapshot's sequence number (0 when there is no snapshot).
+func (db *InMemoryDB) loadSnapshot(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return 0, err
+	}
+	for _, user := range snap.Users {
+		db.putUser(user.toUser())
+	}
+	for _, goal := range snap.Goals {
+		db.putGoal(goal)
+	}
+	for _, contribution := range snap.Contributions {
+		db.appendContribution(contribution)
+	}
+	for _, period := range snap.Periods {
+		db.appendPeriod(period)
+	}
+	for _, category := range snap.Categories {
+		db.putCategory(category)
+	}
+	for _, member := range snap.Members {
+		db.putMember(member)
+	}
+	for _, invitation := range snap.Invitations {
+		db.putInvitation(invitation)
+	}
+	for _, template := range snap.Templates {
+		db.putTemplate(template)
+	}
+	return snap.Seq, nil
+}
+
+// replayWAL applies every intact record in file whose sequence number is
+// greater than afterSeq. A torn record at the end of the file (from a crash
+// during append) is truncated away. Corruption anywhere else is an error,
+// since silently dropping records in the middle would lose data.
+// Returns the last sequence number seen and how many records were applied.
+func (db *InMemoryDB) replayWAL(file *os.File, afterSeq uint64) (uint64, int, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+	size := info.Size()
+
+	reader := bufio.NewReader(file)
+	lastSeq := afterSeq
+	applied := 0
+	var offset int64 // start of the next record
+
+	for offset < size {
+		header := make([]byte, walHeaderSize)
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return lastSeq, applied, truncateTornRecord(file, offset, "incomplete header")
+		}
+		length := int64(binary.BigEndian.Uint32(header[0:4]))
+		checksum := binary.BigEndian.Uint32(header[4:8])
+
+		end := offset + walHeaderSize + length
+		if end > size {
+			return lastSeq, applied, truncateTornRecord(file, offset, "incomplete payload")
+		}
+
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return lastSeq, applied, err
+		}
+		if crc32.ChecksumIEEE(payload) != checksum {
+			if end == size {
+				return lastSeq, applied, truncateTornRecord(file, offset, "checksum mismatch")
+			}
+			return lastSeq, applied, fmt.Errorf("checksum mismatch at offset %d", offset)
+		}
+
+		var rec walRecord
+		if err := json.Unmarshal(payload, &rec); err != nil {
+			return lastSeq, applied, fmt.Errorf("decode record at offset %d: %w", offset, err)
+		}
+
+		// Records at or below the snapshot sequence are already in the snapshot
+		if rec.Seq > afterSeq {
+			if err := db.applyRecord(rec); err != nil {
+				return lastSeq, applied, fmt.Errorf("apply record %d (%s): %w", rec.Seq, rec.Op, err)
+			}
+			lastSeq = rec.Seq
+			applied++
+		}
+		offset = end
+	}
+	return lastSeq, applied, nil
+}
+
+// applyRecord performs a logged mutation on the maps without logging it again.
+func (db *InMemoryDB) applyRecord(rec walRecord) error {
+	switch rec.Op {
+	case opCreateUser, opUpdateUser:
+		db.putUser(rec.User.toUser())
+	case opDeleteUser:
+		db.removeUser(rec.Email)
+	case opCreateGoal, opUpdateGoal:
+		db.putGoal(rec.Goal)
+	case opDeleteGoal:
+		db.removeGoal(rec.ID)
+	case opAddContribution:
+		db.putGoal(rec.Goal)
+		db.appendContribution(rec.Contribution)
+	case opAddContributions:
+		for i, goal := range rec.Goals {
+			db.putGoal(goal)
+			db.appendContribution(rec.Contributions[i])
+		}
+	case opRollOverGoal:
+		db.putGoal(rec.Goal)
+		for _, period := range rec.Periods {
+			db.appendPeriod(period)
+		}
+	case opCreateCategory, opUpdateCategory:
+		db.putCategory(rec.Category)
+	case opDeleteCategory:
+		db.removeCategory(rec.ID)
+	case opPutMember:
+		db.putMember(rec.Member)
+	case opRemoveMember:
+		db.removeMember(rec.Member.GoalID, rec.Member.UserID)
+	case opPutInvitation:
+		db.putInvitation(rec.Invitation)
+	case opRespondInvitation:
+		db.putInvitation(rec.Invitation)
+		if rec.Member != nil {
+			db.putMember(rec.Member)
+		}
+	case opDeleteInvitation:
+		delete(db.invitations, rec.ID)
+	case opPutTemplate:
+		db.putTemplate(rec.Template)
+	case opDeleteTemplate:
+		delete(db.templates, rec.ID)
+	default:
+		return fmt.Errorf("unknown operation %q", rec.Op)
+	}
+	return nil
+}
+
+// truncateTornRecord cuts the WAL at offset, dropping a partially written
+// final record left behind by a crash.
+func truncateTornRecord(file *os.File, offset int64, reason string) error {
+	log.Printf("WAL: dropping torn record at offset %d (%s)", offset, reason)
+	if err := file.Truncate(offset); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// writeFileSync writes data to path and fsyncs it before returning.
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// syncDir fsyncs a directory so a rename inside it is durable.
+// Errors are ignored because some platforms don't support syncing directories.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
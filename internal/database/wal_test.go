@@ -0,0 +1,163 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-api-server/internal/models"
+)
+
+// faultyFile wraps the WAL file and fails the next write after writing only
+// part of it, like a disk filling up mid-record.
+type faultyFile struct {
+	walFile
+	failWrite    bool
+	failTruncate bool
+}
+
+var errDiskFull = errors.New("no space left on device")
+
+func (f *faultyFile) Write(p []byte) (int, error) {
+	if f.failWrite {
+		f.failWrite = false
+		n, _ := f.walFile.Write(p[:len(p)/2])
+		return n, errDiskFull
+	}
+	return f.walFile.Write(p)
+}
+
+func (f *faultyFile) Truncate(size int64) error {
+	if f.failTruncate {
+		return errDiskFull
+	}
+	return f.walFile.Truncate(size)
+}
+
+func newTestUser(id string) *models.User {
+	now := time.Now()
+	return &models.User{ID: id, Email: id + "@example.com", CreatedAt: now, UpdatedAt: now}
+}
+
+// TestWALRewindsFailedWrite checks that a partially written record is cut off,
+// so later records still replay after a restart.
+func TestWALRewindsFailedWrite(t *testing.T) {
+	dir := t.TempDir()
+	db, err := OpenDurableInMemoryDB(dir, DurabilityOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CreateUser(newTestUser("before")); err != nil {
+		t.Fatal(err)
+	}
+
+	file := &faultyFile{walFile: db.wal.file, failWrite: true}
+	db.wal.file = file
+	if err := db.CreateUser(newTestUser("torn")); !errors.Is(err, errDiskFull) {
+		t.Fatalf("CreateUser = %v, want %v", err, errDiskFull)
+	}
+	if _, err := db.GetUserByID("torn"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("user of the failed write was applied: %v", err)
+	}
+	if err := db.CreateUser(newTestUser("after")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenDurableInMemoryDB(dir, DurabilityOptions{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	for _, id := range []string{"before", "after"} {
+		if _, err := reopened.GetUserByID(id); err != nil {
+			t.Errorf("user %q after replay: %v", id, err)
+		}
+	}
+	if _, err := reopened.GetUserByID("torn"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("user %q after replay: %v, want %v", "torn", err, ErrUserNotFound)
+	}
+}
+
+// TestWALUnusableAfterFailedRewind checks that mutations stop once a torn
+// record can't be cut off, instead of being appended after it.
+func TestWALUnusableAfterFailedRewind(t *testing.T) {
+	db, err := OpenDurableInMemoryDB(t.TempDir(), DurabilityOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	db.wal.file = &faultyFile{walFile: db.wal.file, failWrite: true, failTruncate: true}
+	if err := db.CreateUser(newTestUser("torn")); !errors.Is(err, errDiskFull) {
+		t.Fatalf("CreateUser = %v, want %v", err, errDiskFull)
+	}
+	if err := db.CreateUser(newTestUser("after")); !errors.Is(err, ErrWALUnusable) {
+		t.Fatalf("CreateUser = %v, want %v", err, ErrWALUnusable)
+	}
+	if _, err := db.GetUserByID("after"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("user written to an unusable WAL was applied: %v", err)
+	}
+}
+
+// TestWALDropsTornFinalRecord simulates a crash in the middle of an append:
+// on startup the partial record is cut off and the ones before it replayed.
+func TestWALDropsTornFinalRecord(t *testing.T) {
+	dir := t.TempDir()
+	db, err := OpenDurableInMemoryDB(dir, DurabilityOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"first", "second", "third"} {
+		if err := db.CreateUser(newTestUser(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, walFileName)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size()-5); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = OpenDurableInMemoryDB(dir, DurabilityOptions{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	for _, id := range []string{"first", "second"} {
+		if _, err := db.GetUserByID(id); err != nil {
+			t.Errorf("user %q after replay: %v", id, err)
+		}
+	}
+	if _, err := db.GetUserByID("third"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("user %q after replay: %v, want %v", "third", err, ErrUserNotFound)
+	}
+
+	// The next record must follow the last intact one, not the torn bytes
+	if err := db.CreateUser(newTestUser("fourth")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	db, err = OpenDurableInMemoryDB(dir, DurabilityOptions{})
+	if err != nil {
+		t.Fatalf("second reopen: %v", err)
+	}
+	defer db.Close()
+	for _, id := range []string{"first", "second", "fourth"} {
+		if _, err := db.GetUserByID(id); err != nil {
+			t.Errorf("user %q after second replay: %v", id, err)
+		}
+	}
+}
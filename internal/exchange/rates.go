@@ -0,0 +1,310 @@
+// Package exchange keeps the table of currency exchange rates used to
+// convert goal amounts into a user's preferred currency.
+//
+// Rates are managed by admins: they are loaded from a local CSV or JSON file
+// at startup and can be replaced or reloaded through the admin API.
+// There is no live rate feed; conversions are only as fresh as the table.
+package exchange
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-api-server/internal/models"
+)
+
+// ErrNoRate is returned when the table has no rate for a currency.
+var ErrNoRate = errors.New("no exchange rate")
+
+// Rates is the exchange-rate table as it appears in the JSON file and API.
+// Each rate is how many units of Base one unit of the currency is worth,
+// written as a decimal string so no precision is lost:
+//
+//	{"base": "USD", "rates": {"EUR": "1.08", "KRW": "0.00073"}}
+type Rates struct {
+	Base      string            `json:"base" binding:"required,len=3"`
+	Rates     map[string]string `json:"rates" binding:"required"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// Table is a concurrency-safe exchange-rate table.
+// The zero value is not usable; create one with NewTable.
+type Table struct {
+	mu sync.RWMutex
+
+	// path is the file the table is loaded from and saved to ("" for none)
+	path string
+
+	base      string
+	rates     map[string]*big.Rat
+	updatedAt time.Time
+}
+
+// NewTable creates an empty table backed by the file at path.
+// The file isn't read until Reload is called; path may be empty, in which
+// case rates can only be set through Replace and are not saved.
+func NewTable(path string) *Table {
+	return &Table{
+		path:  path,
+		base:  models.DefaultCurrency,
+		rates: map[string]*big.Rat{},
+	}
+}
+
+// Reload replaces the table with the contents of its file.
+// Files ending in .csv are read as CSV, anything else as JSON.
+// On error the current rates are kept.
+func (t *Table) Reload() error {
+	if t.path == "" {
+		return errors.New("no exchange-rate file configured")
+	}
+
+	file, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var rates Rates
+	if strings.EqualFold(filepath.Ext(t.path), ".csv") {
+		rates, err = readCSV(file)
+	} else {
+		err = json.NewDecoder(file).Decode(&rates)
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %w", t.path, err)
+	}
+
+	if rates.UpdatedAt.IsZero() {
+		if info, err := file.Stat(); err == nil {
+			rates.UpdatedAt = info.ModTime()
+		}
+	}
+	return t.set(rates)
+}
+
+// readCSV parses a CSV file with the header "base,currency,rate":
+//
+//	base,currency,rate
+//	USD,EUR,1.08
+//	USD,KRW,0.00073
+//
+// Every row must use the same base currency.
+func readCSV(r io.Reader) (Rates, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return Rates{}, err
+	}
+	if len(records) == 0 || strings.Join(records[0], ",") != "base,currency,rate" {
+		return Rates{}, errors.New(`CSV must start with the header "base,currency,rate"`)
+	}
+
+	rates := Rates{Rates: map[string]string{}}
+	for i, record := range records[1:] {
+		base := strings.ToUpper(strings.TrimSpace(record[0]))
+		if rates.Base == "" {
+			rates.Base = base
+		} else if base != rates.Base {
+			return Rates{}, fmt.Errorf("line %d: base %s differs from %s", i+2, base, rates.Base)
+		}
+		rates.Rates[strings.ToUpper(strings.TrimSpace(record[1]))] = strings.TrimSpace(record[2])
+	}
+	if rates.Base == "" {
+		return Rates{}, errors.New("CSV has no rates")
+	}
+	return rates, nil
+}
+
+// Replace validates rates, saves them to the table's file (if any) and
+// makes them the current table.
+func (t *Table) Replace(rates Rates) error {
+	if rates.UpdatedAt.IsZero() {
+		rates.UpdatedAt = time.Now()
+	}
+	if err := t.set(rates); err != nil {
+		return err
+	}
+	if t.path == "" {
+		return nil
+	}
+	return t.save()
+}
+
+// set validates rates and swaps them in.
+func (t *Table) set(rates Rates) error {
+	base := strings.ToUpper(rates.Base)
+	if _, ok := models.CurrencyExponent(base); !ok {
+		return fmt.Errorf("%w %q", models.ErrUnknownCurrency, rates.Base)
+	}
+
+	parsed := make(map[string]*big.Rat, len(rates.Rates))
+	for code, value := range rates.Rates {
+		code = strings.ToUpper(code)
+		if _, ok := models.CurrencyExponent(code); !ok {
+			return fmt.Errorf("%w %q", models.ErrUnknownCurrency, code)
+		}
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok || rate.Sign() <= 0 {
+			return fmt.Errorf("rate for %s must be a positive decimal, got %q", code, value)
+		}
+		parsed[code] = rate
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.base = base
+	t.rates = parsed
+	t.updatedAt = rates.UpdatedAt
+	return nil
+}
+
+// save writes the current table to its file as JSON (CSV files keep CSV).
+// The file is replaced atomically so a crash can't leave half a table.
+func (t *Table) save() error {
+	current := t.Rates()
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(t.path), ".csv") {
+		var sb strings.Builder
+		w := csv.NewWriter(&sb)
+		w.Write([]string{"base", "currency", "rate"})
+		for _, code := range sortedKeys(current.Rates) {
+			w.Write([]string{current.Base, code, current.Rates[code]})
+		}
+		w.Flush()
+		data = []byte(sb.String())
+	} else {
+		var err error
+		if data, err = json.MarshalIndent(current, "", "  "); err != nil {
+			return err
+		}
+	}
+
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.path)
+}
+
+// Rates returns a copy of the current table.
+func (t *Table) Rates() Rates {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rates := Rates{Base: t.base, Rates: make(map[string]string, len(t.rates)), UpdatedAt: t.updatedAt}
+	for code, rate := range t.rates {
+		rates.Rates[code] = rate.FloatString(rateDigits(rate))
+	}
+	return rates
+}
+
+// rateDigits returns enough decimal places to print rate exactly
+// (rates come from decimal strings, so their denominators are powers of ten).
+func rateDigits(rate *big.Rat) int {
+	digits := 0
+	for new(big.Int).Rem(pow10(digits), rate.Denom()).Sign() != 0 && digits < 30 {
+		digits++
+	}
+	return digits
+}
+
+// sortedKeys returns the map's keys in alphabetical order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// rateToBase returns how many units of the base one unit of code is worth.
+// Must be called with t.mu held.
+func (t *Table) rateToBase(code string) (*big.Rat, error) {
+	if code == t.base {
+		return big.NewRat(1, 1), nil
+	}
+	rate, ok := t.rates[code]
+	if !ok {
+		return nil, fmt.Errorf("%w for %s", ErrNoRate, code)
+	}
+	return rate, nil
+}
+
+// Convert returns amount expressed in the currency to, rounded half away
+// from zero to that currency's minor unit. Converting to the same currency
+// returns the amount unchanged.
+func (t *Table) Convert(amount models.Money, to string) (models.Money, error) {
+	from := amount.Currency
+	if from == "" {
+		from = models.DefaultCurrency
+	}
+	if from == to {
+		return models.NewMoney(amount.Minor, to), nil
+	}
+
+	fromExp, ok := models.CurrencyExponent(from)
+	if !ok {
+		return models.Money{}, fmt.Errorf("%w %q", models.ErrUnknownCurrency, from)
+	}
+	toExp, ok := models.CurrencyExponent(to)
+	if !ok {
+		return models.Money{}, fmt.Errorf("%w %q", models.ErrUnknownCurrency, to)
+	}
+
+	t.mu.RLock()
+	fromRate, err := t.rateToBase(from)
+	if err != nil {
+		t.mu.RUnlock()
+		return models.Money{}, err
+	}
+	toRate, err := t.rateToBase(to)
+	t.mu.RUnlock()
+	if err != nil {
+		return models.Money{}, err
+	}
+
+	// minor(to) = minor(from) / 10^fromExp * fromRate / toRate * 10^toExp
+	value := new(big.Rat).SetInt64(amount.Minor)
+	value.Mul(value, fromRate)
+	value.Quo(value, toRate)
+	value.Mul(value, new(big.Rat).SetFrac(pow10(toExp), pow10(fromExp)))
+
+	minor := roundHalfAwayFromZero(value)
+	if !minor.IsInt64() {
+		return models.Money{}, fmt.Errorf("converted %w", models.ErrAmountOverflow)
+	}
+	return models.NewMoney(minor.Int64(), to), nil
+}
+
+// pow10 returns 10^exp.
+func pow10(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
+
+// roundHalfAwayFromZero rounds value to the nearest integer, with halves
+// rounded away from zero (2.5 -> 3, -2.5 -> -3).
+func roundHalfAwayFromZero(value *big.Rat) *big.Int {
+	num := new(big.Int).Abs(value.Num())
+	denom := value.Denom()
+
+	// (2*|num| + denom) / (2*denom) is |value| rounded half up
+	twice := new(big.Int).Mul(num, big.NewInt(2))
+	twice.Add(twice, denom)
+	rounded := twice.Quo(twice, new(big.Int).Mul(denom, big.NewInt(2)))
+	if value.Sign() < 0 {
+		rounded.Neg(rounded)
+	}
+	return rounded
+}
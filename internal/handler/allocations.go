@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go-api-server/internal/database"
+	"go-api-server/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// checkAllocationTargets reports per-goal problems the binding tags can't
+// express: which of amount and percent the strategy needs, goals listed twice
+// and percentages adding up to more than 100.
+func checkAllocationTargets(req models.AllocationRequest) fieldErrors {
+	fields := fieldErrors{}
+	seen := make(map[string]bool, len(req.Goals))
+	percent := 0
+
+	for i, target := range req.Goals {
+		key := fmt.Sprintf("goals[%d]", i)
+		switch {
+		case seen[target.GoalID]:
+			fields[key+".goal_id"] = "is listed twice"
+		case req.Strategy == models.AllocateFixed && (target.Amount == nil || target.Percent != 0):
+			fields[key+".amount"] = "is required (and percent not allowed) with the fixed strategy"
+		case req.Strategy == models.AllocatePercent && (target.Percent == 0 || target.Amount != nil):
+			fields[key+".percent"] = "is required (and amount not allowed) with the percent strategy"
+		case req.Strategy == models.AllocatePriority && (target.Amount != nil || target.Percent != 0):
+			fields[key] = "takes neither amount nor percent with the priority strategy"
+		}
+		seen[target.GoalID] = true
+		percent += target.Percent
+	}
+	if req.Strategy == models.AllocatePercent && percent > 100 {
+		fields["goals"] = "percentages add up to more than 100"
+	}
+
+	if len(fields) > 0 {
+		return fields
+	}
+	return nil
+}
+
+// AllocateHandler splits one deposit across several goals by fixed amounts,
+// percentages or priority order (see models.AllocationStrategy).
+// All goals must be active and in the same currency, and the user must be
+// allowed to deposit to each of them. Every goal is updated in one atomic step, and each
+// goal that gets money records its own contribution in its ledger.
+func (h *Handler) AllocateHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.AllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if fields := bindingFieldErrors(err); fields != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fields})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if fields := checkAllocationTargets(req); fields != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fields})
+		return
+	}
+
+	// Streaks follow the depositor's time zone, as for single deposits
+	user, err := h.goalOwner(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		return
+	}
+	loc := user.Location()
+
+	// Memberships are looked up first, since the store can't be used from
+	// inside the atomic step
+	members := make(map[string]*models.GoalMember, len(req.Goals))
+	contributions := make([]*models.Contribution, len(req.Goals))
+	now := time.Now()
+	for i, target := range req.Goals {
+		if members[target.GoalID], err = h.goalMembership(target.GoalID, user.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve goal"})
+			return
+		}
+		contributions[i] = &models.Contribution{
+			ID:        uuid.New().String(),
+			GoalID:    target.GoalID,
+			UserID:    user.ID,
+			Kind:      models.ContributionDeposit,
+			Note:      req.Note,
+			Source:    req.Source,
+			CreatedAt: now,
+		}
+		if contributions[i].Source == "" {
+			contributions[i].Source = models.ContributionSourceManual
+		}
+	}
+
+	// The split is worked out inside the atomic step, so priority order
+	// fills the goals based on their progress as it is saved
+	var total, unallocated models.Money
+	goals, err := h.DB.AddContributions(contributions, func(goals []*models.Goal) error {
+		var err error
+		currency := goals[0].Currency()
+		amounts := make([]models.Money, len(goals))
+		for i, goal := range goals {
+			if goal.Deleted() {
+				return database.ErrGoalNotFound
+			}
+			if !models.RoleOf(goal, user.ID, members[goal.ID]).CanContribute() {
+				return errForbidden
+			}
+			if !goal.Active() {
+				return fmt.Errorf("%w: %q is %s", models.ErrGoalNotActive, goal.Title, goal.Status)
+			}
+			if goal.Currency() != currency {
+				return fmt.Errorf("%w: all goals of an allocation must be in the same currency", models.ErrCurrencyMismatch)
+			}
+			if fixed := req.Goals[i].Amount; fixed != nil {
+				if amounts[i], err = fixed.Resolve(currency); err != nil {
+					return err
+				}
+			}
+		}
+
+		// A plain number is taken to be in the goals' currency
+		if total, err = req.Amount.Resolve(currency); err != nil {
+			return err
+		}
+		shares, rest, err := req.Split(total, amounts, goals)
+		if err != nil {
+			return err
+		}
+		for i, share := range shares {
+			contributions[i].Amount = share
+			if !share.IsZero() {
+				goals[i].Streaks.Record(now, loc)
+			}
+		}
+		unallocated = rest
+		return nil
+	})
+	switch {
+	case errors.Is(err, database.ErrGoalNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		return
+	case errors.Is(err, errForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	case errors.Is(err, models.ErrGoalNotActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, models.ErrCurrencyMismatch), errors.Is(err, models.ErrTooPrecise),
+		errors.Is(err, models.ErrAllocationTooLarge):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, models.ErrAmountOverflow):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Amount would take a goal's progress past the largest supported amount"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update goals"})
+		return
+	}
+
+	recorded := []*models.Contribution{}
+	for _, contribution := range contributions {
+		if !contribution.Amount.IsZero() {
+			recorded = append(recorded, contribution)
+		}
+	}
+	if len(recorded) > 0 {
+		h.recordUserStreak(user.ID, now, loc)
+	}
+
+	allocated, _ := total.Sub(unallocated)
+	c.JSON(http.StatusCreated, models.AllocationResult{
+		Strategy:      req.Strategy,
+		Amount:        total,
+		Allocated:     allocated,
+		Unallocated:   unallocated,
+		Contributions: recorded,
+		Goals:         goals,
+	})
+}
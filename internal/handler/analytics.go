@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"go-api-server/internal/exchange"
+	"go-api-server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAnalyticsHandler reports how the authenticated user has been saving
+// across the goals they own: the net amount saved per week or month
+// (?period=week|month, ?periods=N), the average deposit, how many past goals
+// reached their target, and a forecast of when each active goal will be
+// completed, both at its average pace so far (linear) and at its recent pace
+// (moving average). Amounts are converted into the user's default currency
+// (or ?currency=XXX); forecasts stay in each goal's currency.
+// Deleted goals are left out.
+func (h *Handler) GetAnalyticsHandler(c *gin.Context) {
+	user := h.currentUser(c)
+	if user == nil {
+		return
+	}
+
+	var query models.AnalyticsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		if fields := bindingFieldErrors(err); fields != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "fields": fields})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query.Normalize()
+
+	if query.Currency == "" {
+		query.Currency = user.PreferredCurrency()
+	}
+	currency, ok := models.NormalizeCurrency(query.Currency)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported currency " + query.Currency})
+		return
+	}
+
+	goals, err := h.DB.GetGoalsByUserID(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve goals"})
+		return
+	}
+	sort.Slice(goals, func(i, j int) bool {
+		if !goals[i].CreatedAt.Equal(goals[j].CreatedAt) {
+			return goals[i].CreatedAt.Before(goals[j].CreatedAt)
+		}
+		return goals[i].ID < goals[j].ID
+	})
+
+	now := time.Now()
+	loc := user.Location()
+	periods := query.SavedPeriods(now, loc)
+
+	// Sum the ledgers per period and currency first, so each sum is converted once
+	saved := make([]map[string]models.Money, len(periods))
+	for i := range saved {
+		saved[i] = map[string]models.Money{}
+	}
+	deposited := map[string]models.Money{}
+	deposits := 0
+	add := func(sums map[string]models.Money, code string, amount models.Money) error {
+		sum, exists := sums[code]
+		if !exists {
+			sum = models.NewMoney(0, code)
+		}
+		sum, err := sum.Add(amount)
+		if err != nil {
+			return err
+		}
+		sums[code] = sum
+		return nil
+	}
+
+	var live []*models.Goal
+	var archived []*models.GoalPeriod
+	forecasts := []models.GoalForecast{}
+	for _, goal := range goals {
+		if goal.Deleted() {
+			continue
+		}
+		live = append(live, goal)
+
+		ledger, err := h.DB.ContributionHistory(goal.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve contributions"})
+			return
+		}
+		code := goal.Currency()
+		for _, contribution := range ledger {
+			i := sort.Search(len(periods), func(i int) bool {
+				return contribution.CreatedAt.Before(periods[i].End)
+			})
+			deposit := contribution.Kind == models.ContributionDeposit
+			if i < len(periods) && periods[i].Contains(contribution.CreatedAt) {
+				if err := add(saved[i], code, contribution.Amount); err != nil {
+					respondAnalyticsError(c, err)
+					return
+				}
+				if deposit {
+					periods[i].Deposits++
+				}
+			}
+			if deposit {
+				if err := add(deposited, code, contribution.Amount); err != nil {
+					respondAnalyticsError(c, err)
+					return
+				}
+				deposits++
+			}
+		}
+
+		if goal.Period > 1 {
+			// A goal has archived one period less than the number it is in
+			page, _, err := h.DB.ListGoalPeriods(goal.ID, goal.Period-1, 0)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve periods"})
+				return
+			}
+			archived = append(archived, page...)
+		}
+
+		if goal.Active() && !goal.Completed {
+			forecasts = append(forecasts, models.ForecastGoal(goal, ledger, now))
+		}
+	}
+
+	analytics := models.Analytics{
+		Currency:       currency,
+		TimeZone:       loc.String(),
+		Period:         query.Period,
+		SavedPerPeriod: periods,
+		Deposits:       deposits,
+		AverageDeposit: models.NewMoney(0, currency),
+		Forecasts:      forecasts,
+	}
+	analytics.FinishedGoals, analytics.CompletedGoals, analytics.CompletionRate = models.CompletionRate(live, archived, now)
+
+	// Convert the sums, collecting every currency without a rate
+	missing := map[string]bool{}
+	convert := func(sums map[string]models.Money) (models.Money, error) {
+		total := models.NewMoney(0, currency)
+		for code, sum := range sums {
+			converted, err := h.Rates.Convert(sum, currency)
+			if errors.Is(err, exchange.ErrNoRate) {
+				missing[code] = true
+				continue
+			}
+			if err != nil {
+				return total, err
+			}
+			if total, err = total.Add(converted); err != nil {
+				return total, err
+			}
+			if code != currency {
+				updatedAt := h.Rates.Rates().UpdatedAt
+				analytics.RatesUpdatedAt = &updatedAt
+			}
+		}
+		return total, nil
+	}
+	for i := range periods {
+		if periods[i].Saved, err = convert(saved[i]); err != nil {
+			break
+		}
+	}
+	var total models.Money
+	if err == nil {
+		total, err = convert(deposited)
+	}
+	if err != nil {
+		respondAnalyticsError(c, err)
+		return
+	}
+	if deposits > 0 {
+		// Round half up (deposits are positive), without adding to a total
+		// that may be close to the largest amount
+		count := int64(deposits)
+		analytics.AverageDeposit.Minor = total.Minor / count
+		if total.Minor%count >= (count+1)/2 {
+			analytics.AverageDeposit.Minor++
+		}
+	}
+
+	if len(missing) > 0 {
+		codes := make([]string, 0, len(missing))
+		for code := range missing {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":         "No exchange rate to " + currency + " for some of your goals",
+			"missing_rates": codes,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
+
+// respondAnalyticsError reports a failure to add up or convert the amounts.
+func respondAnalyticsError(c *gin.Context, err error) {
+	if errors.Is(err, models.ErrAmountOverflow) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Your savings add up to more than the largest supported amount"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert amounts"})
+}
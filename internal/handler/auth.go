@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -13,17 +14,12 @@ import (
 	"go-api-server/internal/utils"
 )
 
-// DB is the global database instance.
-// In production, you'd typically use dependency injection instead of a global variable.
-// We'll initialize this in main.go and use it across all handlers.
-var DB *database.InMemoryDB
-
 // SignupHandler handles user registration requests.
 // It creates a new user account with a hashed password and returns a JWT token.
 // POST /signup
 // Request body: { "email": "user@example.com", "password": "password123" }
 // Response: { "token": "jwt-token-here", "user": { "id": "...", "email": "...", "created_at": "..." } }
-func SignupHandler(c *gin.Context) {
+func (h *Handler) SignupHandler(c *gin.Context) {
 	// Parse and validate the request body
 	var req models.SignupRequest
 	
@@ -37,8 +33,29 @@ func SignupHandler(c *gin.Context) {
 		return
 	}
 	
+	// The default currency must be one we know how to store
+	currency, ok := models.NormalizeCurrency(req.DefaultCurrency)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request: unsupported currency " + req.DefaultCurrency,
+		})
+		return
+	}
+	
+	// The time zone must be a valid IANA name such as "Asia/Seoul"
+	loc, err := models.LoadTimeZone(req.TimeZone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request: " + err.Error(),
+		})
+		return
+	}
+	
+	// Emails are stored lowercased so every lookup matches however they are typed
+	email := models.NormalizeEmail(req.Email)
+	
 	// Check if a user with this email already exists
-	_, err := DB.GetUserByEmail(req.Email)
+	_, err = h.DB.GetUserByEmail(email)
 	if err == nil {
 		// If err is nil, it means we found a user (GetUserByEmail succeeded)
 		c.JSON(http.StatusConflict, gin.H{
@@ -46,6 +63,13 @@ func SignupHandler(c *gin.Context) {
 		})
 		return
 	}
+	if !errors.Is(err, database.ErrUserNotFound) {
+		// Any other error means the storage backend itself failed
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to look up user",
+		})
+		return
+	}
 	
 	// Hash the password before storing it
 	// NEVER store plain text passwords! bcrypt is a secure hashing algorithm
@@ -62,16 +86,25 @@ func SignupHandler(c *gin.Context) {
 	// Create a new user instance
 	user := &models.User{
 		// Generate a unique ID using UUID (Universally Unique Identifier)
-		ID:        uuid.New().String(),
-		Email:     req.Email,
+		ID:              uuid.New().String(),
+		Email:           email,
 		// Store the hashed password, not the plain text one
-		Password:  string(hashedPassword),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Password:        string(hashedPassword),
+		DefaultCurrency: currency,
+		TimeZone:        loc.String(),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 	
 	// Save the user to the database
-	if err := DB.CreateUser(user); err != nil {
+	if err := h.DB.CreateUser(user); err != nil {
+		// Another request may have registered the same email in the meantime
+		if errors.Is(err, database.ErrUserExists) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "User with this email already exists",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to create user: " + err.Error(),
 		})
@@ -92,11 +125,7 @@ func SignupHandler(c *gin.Context) {
 	// Note: We don't include the password in the response
 	c.JSON(http.StatusCreated, models.AuthResponse{
 		Token: token,
-		User: models.UserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt,
-		},
+		User:  models.NewUserResponse(user),
 	})
 }
 
@@ -105,7 +134,7 @@ func SignupHandler(c *gin.Context) {
 // POST /login
 // Request body: { "email": "user@example.com", "password": "password123" }
 // Response: { "token": "jwt-token-here", "user": { "id": "...", "email": "...", "created_at": "..." } }
-func LoginHandler(c *gin.Context) {
+func (h *Handler) LoginHandler(c *gin.Context) {
 	// Parse and validate the request body
 	var req models.LoginRequest
 	
@@ -116,8 +145,8 @@ func LoginHandler(c *gin.Context) {
 		return
 	}
 	
-	// Look up the user by email
-	user, err := DB.GetUserByEmail(req.Email)
+	// Look up the user by email (stored lowercased, see SignupHandler)
+	user, err := h.DB.GetUserByEmail(models.NormalizeEmail(req.Email))
 	if err != nil {
 		// User not found - return 401 Unauthorized
 		// Note: We use the same error message for "user not found" and "wrong password"
@@ -151,11 +180,7 @@ func LoginHandler(c *gin.Context) {
 	// Return success response with token and user info
 	c.JSON(http.StatusOK, models.AuthResponse{
 		Token: token,
-		User: models.UserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt,
-		},
+		User:  models.NewUserResponse(user),
 	})
 }
 
@@ -169,7 +194,7 @@ func LoginHandler(c *gin.Context) {
 // POST /logout
 // Headers: Authorization: Bearer <jwt-token>
 // Response: { "message": "Successfully logged out" }
-func LogoutHandler(c *gin.Context) {
+func (h *Handler) LogoutHandler(c *gin.Context) {
 	// Get the token from the Authorization header
 	// Format: "Bearer <token>"
 	authHeader := c.GetHeader("Authorization")
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-api-server/internal/database"
+	"go-api-server/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// bindCategoryRequest reads and cleans up the body of POST/PUT /categories,
+// writing a 400 response and returning false if it is invalid.
+func bindCategoryRequest(c *gin.Context) (models.CategoryRequest, bool) {
+	var req models.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if fields := bindingFieldErrors(err); fields != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fields})
+			return req, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fieldErrors{"name": "must not be empty"}})
+		return req, false
+	}
+	req.Color = strings.ToLower(req.Color)
+	return req, true
+}
+
+// ownCategory loads the category with the given ID for userID, writing a 404
+// or 403 response and returning nil if it can't be used.
+func (h *Handler) ownCategory(c *gin.Context, userID, id string) *models.Category {
+	category, err := h.DB.GetCategoryByID(id)
+	if errors.Is(err, database.ErrCategoryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return nil
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve category"})
+		return nil
+	}
+	if category.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return nil
+	}
+	return category
+}
+
+// checkGoalCategory reports a field error unless id is "" (no category) or
+// one of userID's categories.
+func (h *Handler) checkGoalCategory(userID, id string) (fieldErrors, error) {
+	if id == "" {
+		return nil, nil
+	}
+	category, err := h.DB.GetCategoryByID(id)
+	if errors.Is(err, database.ErrCategoryNotFound) || (err == nil && category.UserID != userID) {
+		return fieldErrors{"category_id": "unknown category"}, nil
+	}
+	return nil, err
+}
+
+// ListCategoriesHandler returns the authenticated user's categories sorted by name.
+func (h *Handler) ListCategoriesHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	categories, err := h.DB.ListCategories(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": categories, "count": len(categories)})
+}
+
+// CreateCategoryHandler adds a category for the authenticated user.
+// Names are unique per user, ignoring case (409 otherwise).
+func (h *Handler) CreateCategoryHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	req, ok := bindCategoryRequest(c)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	category := &models.Category{
+		ID:        uuid.New().String(),
+		UserID:    userID.(string),
+		Name:      req.Name,
+		Color:     req.Color,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err := h.DB.CreateCategory(category)
+	if errors.Is(err, database.ErrCategoryExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A category with this name already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// GetCategoryHandler returns one of the authenticated user's categories.
+func (h *Handler) GetCategoryHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	category := h.ownCategory(c, userID.(string), c.Param("id"))
+	if category == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// UpdateCategoryHandler renames or recolors a category.
+func (h *Handler) UpdateCategoryHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	req, ok := bindCategoryRequest(c)
+	if !ok {
+		return
+	}
+
+	category := h.ownCategory(c, userID.(string), c.Param("id"))
+	if category == nil {
+		return
+	}
+
+	category.Name = req.Name
+	category.Color = req.Color
+	category.UpdatedAt = time.Now()
+
+	err := h.DB.UpdateCategory(category)
+	switch {
+	case errors.Is(err, database.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	case errors.Is(err, database.ErrCategoryExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "A category with this name already exists"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategoryHandler removes a category. Its goals are kept, without a category.
+func (h *Handler) DeleteCategoryHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	category := h.ownCategory(c, userID.(string), c.Param("id"))
+	if category == nil {
+		return
+	}
+
+	err := h.DB.DeleteCategory(category.ID)
+	if errors.Is(err, database.ErrCategoryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
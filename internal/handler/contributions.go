@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-api-server/internal/database"
+	"go-api-server/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// errNegativeProgress is returned from inside AddContribution checks when a
+// withdrawal or correction would take the goal's progress below zero.
+var errNegativeProgress = errors.New("progress would become negative")
+
+// Pagination defaults for list endpoints.
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// parsePagination reads the limit and offset query parameters.
+// limit defaults to defaultPageLimit and is capped at maxPageLimit.
+func parsePagination(c *gin.Context) (limit, offset int, err error) {
+	limit = defaultPageLimit
+	if raw := c.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return 0, 0, errors.New("limit must be a positive integer")
+		}
+		if limit > maxPageLimit {
+			limit = maxPageLimit
+		}
+	}
+	if raw := c.Query("offset"); raw != "" {
+		offset, err = strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return 0, 0, errors.New("offset must be a non-negative integer")
+		}
+	}
+	return limit, offset, nil
+}
+
+// ListContributionsHandler returns a page of a goal's contribution ledger, newest first.
+// Query parameters: limit (default 20, max 100) and offset (default 0).
+func (h *Handler) ListContributionsHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goalID := c.Param("id")
+	goal, _ := h.authorizeGoal(c, goalID, userID.(string), models.GoalRole.CanView)
+	if goal == nil {
+		return
+	}
+
+	contributions, total, err := h.DB.ListContributions(goalID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve contributions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ContributionPage{
+		Contributions: contributions,
+		Total:         total,
+		Limit:         limit,
+		Offset:        offset,
+	})
+}
+
+// recordContribution applies amount to the goal as contribution and writes
+// the response. amount is resolved into the goal's currency, and ownership,
+// If-Match and the "no negative progress" rule are checked, all inside the
+// same atomic step as the update. The user's role on the goal must pass
+// allowed, and the goal must be active (paused and archived goals are frozen).
+// Deposits also extend the goal's and the user's streaks.
+func (h *Handler) recordContribution(c *gin.Context, contribution *models.Contribution, amount models.Money, allowed func(models.GoalRole) bool) {
+	if contribution.Source == "" {
+		contribution.Source = models.ContributionSourceManual
+	}
+
+	// Streak days and weeks follow the time zone of the user making the
+	// deposit (for shared goals, not necessarily the owner's)
+	user, err := h.goalOwner(contribution.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		return
+	}
+	loc := user.Location()
+	deposit := contribution.Kind == models.ContributionDeposit
+
+	// The membership is looked up first, since the store can't be used from
+	// inside the atomic step
+	member, err := h.goalMembership(contribution.GoalID, contribution.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve goal"})
+		return
+	}
+
+	// Check the user's role and apply the change in a single atomic step, so
+	// concurrent updates to the same goal can't overwrite each other
+	var stale *models.Goal
+	goal, err := h.DB.AddContribution(contribution, func(goal *models.Goal) error {
+		if goal.Deleted() {
+			return database.ErrGoalNotFound
+		}
+		if !allowed(models.RoleOf(goal, contribution.UserID, member)) {
+			return errForbidden
+		}
+		if !goal.Active() {
+			return fmt.Errorf("%w (it is %s)", models.ErrGoalNotActive, goal.Status)
+		}
+		if !ifMatch(c, goal) {
+			stale = goal
+			return errPreconditionFailed
+		}
+
+		// A plain number is taken to be in the goal's currency
+		resolved, err := amount.Resolve(goal.Currency())
+		if err != nil {
+			return err
+		}
+		progress, err := goal.CurrentAmount.Add(resolved)
+		if err != nil {
+			return err
+		}
+		if progress.IsNegative() {
+			return errNegativeProgress
+		}
+		contribution.Amount = resolved
+		if deposit {
+			goal.Streaks.Record(contribution.CreatedAt, loc)
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, database.ErrGoalNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		return
+	case errors.Is(err, errForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	case errors.Is(err, errPreconditionFailed):
+		respondPreconditionFailed(c, stale)
+		return
+	case errors.Is(err, models.ErrGoalNotActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, errNegativeProgress):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Amount exceeds the goal's current progress"})
+		return
+	case errors.Is(err, models.ErrAmountOverflow):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Amount would take the goal's progress past the largest supported amount"})
+		return
+	case errors.Is(err, models.ErrCurrencyMismatch), errors.Is(err, models.ErrTooPrecise):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update goal"})
+		return
+	}
+
+	if deposit {
+		h.recordUserStreak(contribution.UserID, contribution.CreatedAt, loc)
+	}
+
+	setGoalETag(c, goal)
+	c.JSON(http.StatusOK, goal)
+}
+
+// recordUserStreak extends userID's streaks with a deposit made at the given
+// time. It runs once the goal is saved, so a failure only costs the user-wide
+// streak this deposit and is logged instead of failing the request.
+func (h *Handler) recordUserStreak(userID string, at time.Time, loc *time.Location) {
+	_, err := h.DB.UpdateUserStreaks(userID, func(streaks *models.Streaks) error {
+		streaks.Record(at, loc)
+		return nil
+	})
+	if err != nil && !errors.Is(err, database.ErrUserNotFound) {
+		log.Printf("Failed to update streaks of user %s: %v", userID, err)
+	}
+}
+
+// WithdrawGoalHandler takes saved money out of a goal (owner only).
+// The withdrawal is recorded in the ledger with its reason; a completed goal
+// that drops below its target is reopened.
+func (h *Handler) WithdrawGoalHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.WithdrawGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	contribution := &models.Contribution{
+		ID:        uuid.New().String(),
+		GoalID:    c.Param("id"),
+		UserID:    userID.(string),
+		Kind:      models.ContributionWithdrawal,
+		Reason:    req.Reason,
+		Source:    req.Source,
+		CreatedAt: time.Now(),
+	}
+	h.recordContribution(c, contribution, req.Amount.Neg(), models.GoalRole.CanManage)
+}
+
+// CorrectGoalHandler adjusts a goal's progress by a signed amount to fix a
+// mistake. Like withdrawals, corrections need a reason, can reopen a goal and
+// can only be made by the owner.
+func (h *Handler) CorrectGoalHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.CorrectGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	contribution := &models.Contribution{
+		ID:        uuid.New().String(),
+		GoalID:    c.Param("id"),
+		UserID:    userID.(string),
+		Kind:      models.ContributionCorrection,
+		Reason:    req.Reason,
+		Source:    req.Source,
+		CreatedAt: time.Now(),
+	}
+	h.recordContribution(c, contribution, req.Amount, models.GoalRole.CanManage)
+}
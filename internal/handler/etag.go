@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-api-server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errPreconditionFailed is returned from inside UpdateGoalFunc callbacks when
+// the client's If-Match header doesn't match the goal's current version.
+var errPreconditionFailed = errors.New("precondition failed")
+
+// goalETag formats a goal's version as a strong ETag, e.g. "3" (with quotes).
+func goalETag(goal *models.Goal) string {
+	return strconv.Quote(strconv.FormatInt(goal.Version, 10))
+}
+
+// setGoalETag adds the goal's ETag to the response headers.
+// Clients send it back in If-Match to make their next change conditional.
+func setGoalETag(c *gin.Context, goal *models.Goal) {
+	c.Header("ETag", goalETag(goal))
+}
+
+// ifNoneMatch reports whether the request's If-None-Match header already
+// names the goal's current ETag, i.e. the client's cached copy is fresh.
+func ifNoneMatch(c *gin.Context, goal *models.Goal) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	return etagListMatches(header, goalETag(goal))
+}
+
+// etagListMatches reports whether a comma-separated If-Match/If-None-Match
+// list contains etag or "*" (a W/ prefix is ignored, since our versions are exact).
+func etagListMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch reports whether the request's If-Match header allows changing goal.
+// A missing header means the change is unconditional. Otherwise the header
+// must be "*" or a comma-separated list containing the goal's ETag.
+func ifMatch(c *gin.Context, goal *models.Goal) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return true
+	}
+	return etagListMatches(header, goalETag(goal))
+}
+
+// respondPreconditionFailed writes the 412 response for a stale If-Match,
+// including the current ETag so the client can refetch and retry.
+func respondPreconditionFailed(c *gin.Context, goal *models.Goal) {
+	setGoalETag(c, goal)
+	c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Goal was modified by another request"})
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"os"
+
+	"go-api-server/internal/exchange"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetExchangeRatesHandler returns the current exchange-rate table.
+func (h *Handler) GetExchangeRatesHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, h.Rates.Rates())
+}
+
+// ReplaceExchangeRatesHandler replaces the whole exchange-rate table (admin only).
+// The new table is also written to the configured rates file, if any.
+// Request body: { "base": "USD", "rates": { "EUR": "1.08", "KRW": "0.00073" } }
+func (h *Handler) ReplaceExchangeRatesHandler(c *gin.Context) {
+	var req exchange.Rates
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.Rates.Replace(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.Rates.Rates())
+}
+
+// ReloadExchangeRatesHandler re-reads the exchange-rate file (admin only),
+// e.g. after it was edited on disk.
+func (h *Handler) ReloadExchangeRatesHandler(c *gin.Context) {
+	err := h.Rates.Reload()
+	if errors.Is(err, os.ErrNotExist) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Exchange-rate file not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.Rates.Rates())
+}
@@ -1,17 +1,25 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"go-api-server/internal/database"
 	"go-api-server/internal/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+// errForbidden is returned from inside UpdateGoalFunc callbacks when the
+// authenticated user doesn't own the goal; it aborts the update.
+var errForbidden = errors.New("forbidden")
+
 // CreateGoalHandler handles the creation of a new savings goal.
-func CreateGoalHandler(c *gin.Context) {
+func (h *Handler) CreateGoalHandler(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
@@ -20,122 +28,412 @@ func CreateGoalHandler(c *gin.Context) {
 
 	var req models.CreateGoalRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
+		if fields := bindingFieldErrors(err); fields != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fields})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.createGoal(c, userID.(string), req)
+}
+
+// createGoal creates a goal for userID from req (already bound and
+// validated) and writes the response.
+func (h *Handler) createGoal(c *gin.Context, userID string, req models.CreateGoalRequest) {
+	// The owner's settings pick the default currency and the time zone
+	// whose calendar the goal's period follows
+	owner, err := h.goalOwner(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		return
+	}
+
+	now := time.Now()
+	start, end, fields := newGoalSchedule(req, now, owner.Location())
+	if fields != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fields})
+		return
+	}
+
+	fields, err = h.checkGoalCategory(owner.ID, req.CategoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve category"})
+		return
+	}
+	if fields != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fields})
+		return
+	}
+
+	currency, err := goalCurrency(owner, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	target, err := req.TargetAmount.Resolve(currency)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	goal := &models.Goal{
 		ID:            uuid.New().String(),
-		UserID:        userID.(string),
+		UserID:        userID,
 		Title:         req.Title,
-		TargetAmount:  req.TargetAmount,
-		CurrentAmount: 0,
+		TargetAmount:  target,
+		CurrentAmount: models.NewMoney(0, target.Currency),
 		Duration:      req.Duration,
-		StartDate:     time.Now(),
-		CreatedAt:     time.Now(),
+		StartDate:     start,
+		EndDate:       end,
+		CreatedAt:     now,
 		Completed:     false,
+		Recurring:     req.Recurring,
+		Period:        1,
+		CategoryID:    req.CategoryID,
+		Tags:          models.NormalizeTags(req.Tags),
 	}
 
-	// Calculate EndDate based on Duration
-	switch req.Duration {
-	case models.Weekly:
-		goal.EndDate = goal.StartDate.AddDate(0, 0, 7)
-	case models.Monthly:
-		goal.EndDate = goal.StartDate.AddDate(0, 1, 0)
-	case models.Yearly:
-		goal.EndDate = goal.StartDate.AddDate(1, 0, 0)
+	milestones, fields := buildMilestones(req.Milestones, currency, nil)
+	if fields != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fields})
+		return
 	}
+	goal.Milestones = milestones
+	goal.UpdateMilestones(now)
 
-	if err := DB.CreateGoal(goal); err != nil {
+	if err := h.DB.CreateGoal(goal); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create goal"})
 		return
 	}
 
+	setGoalETag(c, goal)
 	c.JSON(http.StatusCreated, goal)
 }
 
-// GetGoalsHandler retrieves all goals for the authenticated user.
-func GetGoalsHandler(c *gin.Context) {
+// newGoalSchedule works out the start and end date of a new goal in the
+// owner's time zone loc. The start defaults to now (a later start is allowed);
+// the end defaults to the start plus the duration, ending at midnight in loc,
+// except for custom goals, which must send one.
+// The end must come after the start and must not already have passed.
+func newGoalSchedule(req models.CreateGoalRequest, now time.Time, loc *time.Location) (start, end time.Time, fields fieldErrors) {
+	start = now.In(loc)
+	if req.StartDate != nil {
+		start = req.StartDate.In(loc)
+	}
+
+	switch {
+	case req.EndDate != nil:
+		end = *req.EndDate
+	case req.Duration == models.Custom:
+		return start, end, fieldErrors{"end_date": "is required for custom goals"}
+	default:
+		end = req.Duration.EndDate(start)
+	}
+
+	switch {
+	case !end.After(start):
+		return start, end, fieldErrors{"end_date": "must be after start_date"}
+	case !end.After(now):
+		return start, end, fieldErrors{"end_date": "must be in the future"}
+	}
+	return start, end, nil
+}
+
+// goalOwner loads the user who owns (or is about to own) a goal.
+// A user that no longer exists gets the default settings instead of an error,
+// since the token alone is enough to act on one's goals.
+func (h *Handler) goalOwner(userID string) (*models.User, error) {
+	user, err := h.DB.GetUserByID(userID)
+	if errors.Is(err, database.ErrUserNotFound) {
+		return &models.User{ID: userID}, nil
+	}
+	return user, err
+}
+
+// goalCurrency picks the currency for a new goal: the request's "currency",
+// else the currency written on the target amount, else the owner's default.
+func goalCurrency(owner *models.User, req models.CreateGoalRequest) (string, error) {
+	requested := req.Currency
+	if requested == "" {
+		requested = req.TargetAmount.Currency
+	}
+	if requested == "" {
+		requested = owner.PreferredCurrency()
+	}
+
+	currency, ok := models.NormalizeCurrency(requested)
+	if !ok {
+		return "", fmt.Errorf("%w %q", models.ErrUnknownCurrency, requested)
+	}
+	return currency, nil
+}
+
+// GetGoalsHandler lists the authenticated user's goals one page at a time,
+// including the goals shared with them. Archived and deleted goals are only
+// listed when asked for with status.
+// Query parameters filter (shared, status, completed, duration, q, category, tag,
+// created_after/before, end_after/before), sort (sort=created_at|end_date|progress, order=asc|desc)
+// and paginate (limit, cursor) the list; see models.GoalQuery.
+// The response is {"goals": [...], "next_cursor": "..."}.
+func (h *Handler) GetGoalsHandler(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	goals, err := DB.GetGoalsByUserID(userID.(string))
+	var query models.GoalQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		if fields := bindingFieldErrors(err); fields != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "fields": fields})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query.UserID = userID.(string)
+	query.Tags = models.NormalizeTags(query.Tags)
+
+	page, err := h.DB.ListGoals(query)
+	if errors.Is(err, database.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve goals"})
 		return
 	}
 
-	c.JSON(http.StatusOK, goals)
+	c.JSON(http.StatusOK, page)
 }
 
-// UpdateGoalProgressHandler updates the current amount of a goal.
-func UpdateGoalProgressHandler(c *gin.Context) {
+// GetGoalHandler returns one of the authenticated user's goals together with
+// its progress metrics (percent complete, pace needed, on track, ...).
+// The response carries the goal's ETag; a matching If-None-Match gets 304.
+func (h *Handler) GetGoalHandler(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	goalID := c.Param("id")
-	var req models.UpdateGoalProgressRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	goal, _ := h.authorizeGoal(c, c.Param("id"), userID.(string), models.GoalRole.CanView)
+	if goal == nil {
 		return
 	}
 
-	goal, err := DB.GetGoalByID(goalID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+	setGoalETag(c, goal)
+	if ifNoneMatch(c, goal) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
-	if goal.UserID != userID.(string) {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
-		return
-	}
+	c.JSON(http.StatusOK, models.GoalDetails{
+		Goal:    goal,
+		Metrics: models.ComputeMetrics(goal, time.Now()),
+	})
+}
 
-	goal.CurrentAmount += req.Amount
-	if goal.CurrentAmount >= goal.TargetAmount {
-		goal.Completed = true
-		now := time.Now()
-		goal.CompletedAt = &now
+// UpdateGoalProgressHandler records a deposit in the goal's ledger and adds it
+// to the goal's current amount. The owner and contributors may deposit.
+// If the request has an If-Match header, the update only happens when it
+// matches the goal's current ETag (412 Precondition Failed otherwise).
+func (h *Handler) UpdateGoalProgressHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
 	}
 
-	if err := DB.UpdateGoal(goal); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update goal"})
+	goalID := c.Param("id")
+	var req models.UpdateGoalProgressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, goal)
+	// Every deposit is recorded in the goal's ledger
+	contribution := &models.Contribution{
+		ID:        uuid.New().String(),
+		GoalID:    goalID,
+		UserID:    userID.(string),
+		Kind:      models.ContributionDeposit,
+		Note:      req.Note,
+		Source:    req.Source,
+		CreatedAt: time.Now(),
+	}
+	h.recordContribution(c, contribution, req.Amount, models.GoalRole.CanContribute)
 }
 
-// DeleteGoalHandler removes a goal.
-func DeleteGoalHandler(c *gin.Context) {
+// UpdateGoalHandler edits a goal's title, target amount, dates, milestones,
+// category or tags.
+// Only the fields present in the body change. Changing the target re-evaluates
+// whether the goal is completed. Honors If-Match like the other mutations.
+func (h *Handler) UpdateGoalHandler(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	goalID := c.Param("id")
-	goal, err := DB.GetGoalByID(goalID)
+	var req models.UpdateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if fields := bindingFieldErrors(err); fields != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fields})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	owner, err := h.goalOwner(userID.(string))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
 		return
 	}
 
-	if goal.UserID != userID.(string) {
+	if req.CategoryID != nil {
+		fields, err := h.checkGoalCategory(owner.ID, *req.CategoryID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve category"})
+			return
+		}
+		if fields != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fields})
+			return
+		}
+	}
+
+	// Check ownership, validate against the current goal and apply the edit
+	// in one atomic step
+	var stale *models.Goal
+	goal, err := h.DB.UpdateGoalFunc(c.Param("id"), func(goal *models.Goal) error {
+		if goal.Deleted() {
+			return database.ErrGoalNotFound
+		}
+		// Only the owner may edit a goal, so no membership is needed here
+		if !models.RoleOf(goal, userID.(string), nil).CanManage() {
+			return errForbidden
+		}
+		if !ifMatch(c, goal) {
+			stale = goal
+			return errPreconditionFailed
+		}
+		return applyGoalUpdate(goal, req, time.Now(), owner.Location())
+	})
+
+	var fields fieldErrors
+	switch {
+	case errors.Is(err, database.ErrGoalNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		return
+	case errors.Is(err, errForbidden):
 		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
 		return
+	case errors.Is(err, errPreconditionFailed):
+		respondPreconditionFailed(c, stale)
+		return
+	case errors.As(err, &fields):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fields})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update goal"})
+		return
 	}
 
-	if err := DB.DeleteGoal(goalID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete goal"})
-		return
+	setGoalETag(c, goal)
+	c.JSON(http.StatusOK, goal)
+}
+
+// applyGoalUpdate validates req against goal and applies it.
+// All problems are collected into one fieldErrors, so the client can fix
+// every field at once; nothing is changed if there are any.
+// Dates are checked like on create (see newGoalSchedule): a new start is
+// moved into loc, the owner's zone, end dates derived from the duration
+// follow its calendar, and a changed end must not already have passed.
+func applyGoalUpdate(goal *models.Goal, req models.UpdateGoalRequest, now time.Time, loc *time.Location) error {
+	fields := fieldErrors{}
+	updated := *goal
+
+	if req.Title != nil {
+		updated.Title = strings.TrimSpace(*req.Title)
+		if updated.Title == "" {
+			fields["title"] = "must not be empty"
+		}
+	}
+
+	if req.TargetAmount != nil {
+		target, err := req.TargetAmount.Resolve(goal.Currency())
+		if err != nil {
+			fields["target_amount"] = err.Error()
+		}
+		updated.TargetAmount = target
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Goal deleted successfully"})
+	if req.Duration != nil {
+		updated.Duration = *req.Duration
+	}
+	if req.StartDate != nil {
+		updated.StartDate = req.StartDate.In(loc)
+	}
+
+	// A new start or duration moves the end date along with it, unless the
+	// client sends one or the goal is custom (its end is explicit)
+	if req.EndDate != nil {
+		updated.EndDate = *req.EndDate
+	} else if (req.Duration != nil || req.StartDate != nil) && updated.Duration != models.Custom {
+		updated.EndDate = updated.Duration.EndDate(updated.StartDate)
+	}
+	if req.Recurring != nil {
+		updated.Recurring = *req.Recurring
+	}
+	// The category was checked against the user's categories by the caller
+	if req.CategoryID != nil {
+		updated.CategoryID = *req.CategoryID
+	}
+	if req.Tags != nil {
+		updated.Tags = models.NormalizeTags(*req.Tags)
+	}
+
+	if req.Milestones != nil {
+		milestones, milestoneFields := buildMilestones(*req.Milestones, goal.Currency(), goal.Milestones)
+		for field, message := range milestoneFields {
+			fields[field] = message
+		}
+		updated.Milestones = milestones
+	}
+	if req.Duration != nil || req.StartDate != nil || req.EndDate != nil {
+		switch {
+		case !updated.EndDate.After(updated.StartDate):
+			fields["end_date"] = "must be after start_date"
+		case !updated.EndDate.After(now):
+			fields["end_date"] = "must be in the future"
+		}
+	}
+
+	if len(fields) > 0 {
+		return fields
+	}
+
+	// A new target moves completion and the percent milestones;
+	// new milestones may already be reached
+	if req.TargetAmount != nil || req.Milestones != nil {
+		if err := updated.UpdateCompletion(now); err != nil {
+			return err
+		}
+	}
+	*goal = updated
+	return nil
+}
+
+// DeleteGoalHandler deletes a goal. The goal is only marked as deleted: it
+// disappears from the API but can be restored (POST /goals/:id/restore) until
+// the purge job removes it for good after GOAL_RETENTION.
+// Like progress updates, it honors If-Match.
+func (h *Handler) DeleteGoalHandler(c *gin.Context) {
+	if goal := h.changeGoalStatus(c, false, (*models.Goal).Delete); goal != nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Goal deleted successfully"})
+	}
 }
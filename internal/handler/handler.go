@@ -3,8 +3,43 @@ package handler
 import (
 	"encoding/json"
 	"net/http" // Go's built-in net/http package for HTTP handling
+	"strings"
+
+	"go-api-server/internal/database"
+	"go-api-server/internal/exchange"
 )
 
+// Handler holds the dependencies shared by the Gin handlers.
+// Instead of a global database variable, main.go builds a Handler with the
+// storage backend it picked and the router registers the handler methods.
+// This lets us swap InMemoryDB for another database.Store (or a mock in tests).
+type Handler struct {
+    // DB is the storage backend used by every handler
+    DB database.Store
+
+    // Rates converts amounts between currencies (e.g. for GET /me/totals)
+    Rates *exchange.Table
+
+    // AdminEmails are the users allowed to use the /admin endpoints
+    AdminEmails []string
+}
+
+// NewHandler creates a Handler that reads and writes through the given store
+// and converts currencies with the given exchange-rate table.
+func NewHandler(db database.Store, rates *exchange.Table, adminEmails []string) *Handler {
+    return &Handler{DB: db, Rates: rates, AdminEmails: adminEmails}
+}
+
+// IsAdmin reports whether email belongs to an admin (case-insensitive).
+func (h *Handler) IsAdmin(email string) bool {
+    for _, admin := range h.AdminEmails {
+        if strings.EqualFold(admin, email) {
+            return true
+        }
+    }
+    return false
+}
+
 // GetHandler handles HTTP GET requests.
 // It responds with a JSON object containing a simple greeting message.
 // Parameters:
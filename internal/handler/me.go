@@ -0,0 +1,341 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go-api-server/internal/database"
+	"go-api-server/internal/exchange"
+	"go-api-server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUser loads the authenticated user, writing an error response and
+// returning nil if that fails.
+func (h *Handler) currentUser(c *gin.Context) *models.User {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil
+	}
+
+	user, err := h.DB.GetUserByID(userID.(string))
+	if errors.Is(err, database.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		return nil
+	}
+	return user
+}
+
+// GetMeHandler returns the authenticated user's profile and settings.
+func (h *Handler) GetMeHandler(c *gin.Context) {
+	user := h.currentUser(c)
+	if user == nil {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": models.NewUserResponse(user)})
+}
+
+// UpdateMeHandler changes the authenticated user's settings.
+// Only the fields present in the request body are changed.
+func (h *Handler) UpdateMeHandler(c *gin.Context) {
+	var req models.UpdateMeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := h.currentUser(c)
+	if user == nil {
+		return
+	}
+
+	if req.DefaultCurrency != nil {
+		currency, ok := models.NormalizeCurrency(*req.DefaultCurrency)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported currency " + *req.DefaultCurrency})
+			return
+		}
+		user.DefaultCurrency = currency
+	}
+	if req.TimeZone != nil {
+		loc, err := models.LoadTimeZone(*req.TimeZone)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		user.TimeZone = loc.String()
+	}
+	user.UpdatedAt = time.Now()
+
+	if err := h.DB.UpdateUser(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": models.NewUserResponse(user)})
+}
+
+// GetTotalsHandler reports how much the authenticated user has saved across
+// all goals, converted into their default currency (or ?currency=XXX).
+// Sums are taken per original currency first and converted once, so
+// rounding happens at most once per currency.
+// ?category=ID, ?tag=x (repeatable) and ?status= restrict the totals to
+// matching goals; like GET /goals, archived and deleted goals are left out
+// unless ?status asks for them. ?group_by=category|tag adds a converted
+// subtotal per category or tag.
+func (h *Handler) GetTotalsHandler(c *gin.Context) {
+	user := h.currentUser(c)
+	if user == nil {
+		return
+	}
+
+	currency, ok := models.NormalizeCurrency(c.DefaultQuery("currency", user.PreferredCurrency()))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported currency " + c.Query("currency")})
+		return
+	}
+	groupBy := c.Query("group_by")
+	if groupBy != "" && groupBy != models.GroupByCategory && groupBy != models.GroupByTag {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be category or tag"})
+		return
+	}
+	status := c.Query("status")
+	switch models.GoalStatus(status) {
+	case "", models.GoalActive, models.GoalPaused, models.GoalArchived, models.GoalStatusDeleted:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active, paused, archived or deleted"})
+		return
+	}
+
+	goals, err := h.DB.GetGoalsByUserID(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve goals"})
+		return
+	}
+
+	// The same filters as GET /goals, applied in memory
+	filter := models.GoalQuery{
+		UserID:   user.ID,
+		Status:   status,
+		Category: c.Query("category"),
+		Tags:     models.NormalizeTags(c.QueryArray("tag")),
+	}
+	matching := goals[:0]
+	for _, goal := range goals {
+		if filter.Matches(goal) {
+			matching = append(matching, goal)
+		}
+	}
+
+	totals, missing, err := h.sumGoals(matching, currency)
+	if errors.Is(err, models.ErrAmountOverflow) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Your goals add up to more than the largest supported amount"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert totals"})
+		return
+	}
+	if len(missing) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":         "No exchange rate to " + currency + " for some of your goals",
+			"missing_rates": missing,
+		})
+		return
+	}
+
+	if groupBy != "" {
+		groups, err := h.groupTotals(user.ID, matching, groupBy, currency)
+		if errors.Is(err, models.ErrAmountOverflow) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Your goals add up to more than the largest supported amount"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add up goals"})
+			return
+		}
+		totals.GroupBy = groupBy
+		totals.Groups = groups
+	}
+	c.JSON(http.StatusOK, totals)
+}
+
+// sumGoals adds up goals per currency and converts the sums into currency.
+// Every currency without an exchange rate is returned in missing (sorted),
+// so the client (or admin) sees all of them at once.
+func (h *Handler) sumGoals(goals []*models.Goal, currency string) (totals models.Totals, missing []string, err error) {
+	// Sum the goals per currency; amounts in one currency add up exactly
+	byCurrency := map[string]*models.CurrencyTotal{}
+	for _, goal := range goals {
+		code := goal.Currency()
+		total, exists := byCurrency[code]
+		if !exists {
+			total = &models.CurrencyTotal{
+				Currency: code,
+				Saved:    models.NewMoney(0, code),
+				Target:   models.NewMoney(0, code),
+			}
+			byCurrency[code] = total
+		}
+		total.Goals++
+		if total.Saved, err = total.Saved.Add(goal.CurrentAmount); err == nil {
+			total.Target, err = total.Target.Add(goal.TargetAmount)
+		}
+		if err != nil {
+			return totals, nil, err
+		}
+	}
+
+	totals = models.Totals{
+		Currency:   currency,
+		Saved:      models.NewMoney(0, currency),
+		Target:     models.NewMoney(0, currency),
+		ByCurrency: []models.CurrencyTotal{},
+	}
+
+	// Convert each currency's sums once
+	for _, total := range byCurrency {
+		totals.Goals += total.Goals
+		totals.ByCurrency = append(totals.ByCurrency, *total)
+
+		saved, err := h.Rates.Convert(total.Saved, currency)
+		if errors.Is(err, exchange.ErrNoRate) {
+			missing = append(missing, total.Currency)
+			continue
+		}
+		if err != nil {
+			return totals, nil, err
+		}
+		target, err := h.Rates.Convert(total.Target, currency)
+		if err != nil {
+			return totals, nil, err
+		}
+
+		if totals.Saved, err = totals.Saved.Add(saved); err != nil {
+			return totals, nil, err
+		}
+		if totals.Target, err = totals.Target.Add(target); err != nil {
+			return totals, nil, err
+		}
+		if total.Currency != currency {
+			updatedAt := h.Rates.Rates().UpdatedAt
+			totals.RatesUpdatedAt = &updatedAt
+		}
+	}
+	sort.Strings(missing)
+
+	sort.Slice(totals.ByCurrency, func(i, j int) bool {
+		return totals.ByCurrency[i].Currency < totals.ByCurrency[j].Currency
+	})
+	return totals, missing, nil
+}
+
+// groupTotals sums goals per category or per tag (groupBy), converted into
+// currency. A goal with several tags counts towards each of them; goals
+// without a category (or tag) are summed in a group with an empty key,
+// listed last. The other groups are sorted by name.
+func (h *Handler) groupTotals(userID string, goals []*models.Goal, groupBy, currency string) ([]models.TotalsGroup, error) {
+	names := map[string]string{}
+	members := map[string][]*models.Goal{}
+	if groupBy == models.GroupByCategory {
+		categories, err := h.DB.ListCategories(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, category := range categories {
+			names[category.ID] = category.Name
+		}
+		for _, goal := range goals {
+			members[goal.CategoryID] = append(members[goal.CategoryID], goal)
+		}
+	} else {
+		for _, goal := range goals {
+			if len(goal.Tags) == 0 {
+				members[""] = append(members[""], goal)
+			}
+			for _, tag := range goal.Tags {
+				names[tag] = tag
+				members[tag] = append(members[tag], goal)
+			}
+		}
+	}
+
+	groups := make([]models.TotalsGroup, 0, len(members))
+	for key, subset := range members {
+		// Every currency has a rate here: the caller checked all the goals
+		totals, _, err := h.sumGoals(subset, currency)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, models.TotalsGroup{
+			Key:    key,
+			Name:   names[key],
+			Goals:  totals.Goals,
+			Saved:  totals.Saved,
+			Target: totals.Target,
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if (a.Key == "") != (b.Key == "") {
+			return b.Key == ""
+		}
+		if !strings.EqualFold(a.Name, b.Name) {
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+		return a.Key < b.Key
+	})
+	return groups, nil
+}
+
+// GetStreaksHandler reports the authenticated user's saving streaks: the
+// days and weeks in a row with a deposit to any goal, and to each goal.
+// Streaks that were broken since the last deposit are reported with a
+// current length of 0. Deleted goals are left out.
+func (h *Handler) GetStreaksHandler(c *gin.Context) {
+	user := h.currentUser(c)
+	if user == nil {
+		return
+	}
+
+	goals, err := h.DB.GetGoalsByUserID(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve goals"})
+		return
+	}
+	sort.Slice(goals, func(i, j int) bool {
+		if !goals[i].CreatedAt.Equal(goals[j].CreatedAt) {
+			return goals[i].CreatedAt.Before(goals[j].CreatedAt)
+		}
+		return goals[i].ID < goals[j].ID
+	})
+
+	now := time.Now()
+	loc := user.Location()
+	report := models.StreakReport{
+		TimeZone: loc.String(),
+		Streaks:  user.Streaks.AsOf(now, loc),
+		Goals:    make([]models.GoalStreaks, 0, len(goals)),
+	}
+	for _, goal := range goals {
+		if goal.Deleted() {
+			continue
+		}
+		report.Goals = append(report.Goals, models.GoalStreaks{
+			GoalID:  goal.ID,
+			Title:   goal.Title,
+			Streaks: goal.Streaks.AsOf(now, loc),
+		})
+	}
+	c.JSON(http.StatusOK, report)
+}
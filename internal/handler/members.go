@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"go-api-server/internal/database"
+	"go-api-server/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// goalMembership returns userID's membership of goalID, or nil if the goal
+// isn't shared with them (including when they own it).
+func (h *Handler) goalMembership(goalID, userID string) (*models.GoalMember, error) {
+	member, err := h.DB.GetGoalMember(goalID, userID)
+	if errors.Is(err, database.ErrMemberNotFound) {
+		return nil, nil
+	}
+	return member, err
+}
+
+// authorizeGoal loads the goal with the given ID and checks that userID's
+// role on it (owner, contributor, viewer or none) passes allowed, e.g.
+// models.GoalRole.CanView. Otherwise it writes a 404, 403 or 500 response
+// and returns nil. Deleted goals are not found.
+func (h *Handler) authorizeGoal(c *gin.Context, goalID, userID string, allowed func(models.GoalRole) bool) (*models.Goal, models.GoalRole) {
+	goal, err := h.DB.GetGoalByID(goalID)
+	if errors.Is(err, database.ErrGoalNotFound) || err == nil && goal.Deleted() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		return nil, ""
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve goal"})
+		return nil, ""
+	}
+
+	var member *models.GoalMember
+	if goal.UserID != userID {
+		if member, err = h.goalMembership(goal.ID, userID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve goal"})
+			return nil, ""
+		}
+	}
+
+	role := models.RoleOf(goal, userID, member)
+	if !allowed(role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return nil, ""
+	}
+	return goal, role
+}
+
+// ListMembersHandler lists who a goal is shared with: the owner first, then
+// the members in the order they joined, each with how much they contributed.
+// Anyone the goal is shared with may see it.
+func (h *Handler) ListMembersHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	goal, _ := h.authorizeGoal(c, c.Param("id"), userID.(string), models.GoalRole.CanView)
+	if goal == nil {
+		return
+	}
+
+	members, err := h.DB.ListGoalMembers(goal.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve members"})
+		return
+	}
+	totals, err := h.DB.ContributionTotals(goal.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add up contributions"})
+		return
+	}
+
+	summaries := make([]models.MemberSummary, 0, len(members)+1)
+	summaries = append(summaries, h.memberSummary(goal, goal.UserID, models.RoleOwner, nil, totals))
+	for _, member := range members {
+		joinedAt := member.JoinedAt
+		summaries = append(summaries, h.memberSummary(goal, member.UserID, member.Role, &joinedAt, totals))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"goal_id": goal.ID, "members": summaries, "count": len(summaries)})
+}
+
+// memberSummary describes one person on a goal. The email is left out if the
+// account no longer exists.
+func (h *Handler) memberSummary(goal *models.Goal, userID string, role models.GoalRole, joinedAt *time.Time, totals map[string]models.Money) models.MemberSummary {
+	summary := models.MemberSummary{
+		UserID:      userID,
+		Role:        role,
+		JoinedAt:    joinedAt,
+		Contributed: models.NewMoney(0, goal.Currency()),
+	}
+	if total, exists := totals[userID]; exists {
+		summary.Contributed = total
+	}
+	if user, err := h.DB.GetUserByID(userID); err == nil {
+		summary.Email = user.Email
+	}
+	return summary
+}
+
+// UpdateMemberHandler changes a member's role (contributor or viewer).
+// Only the owner may change roles.
+func (h *Handler) UpdateMemberHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if fields := bindingFieldErrors(err); fields != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fields})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, _ := h.authorizeGoal(c, c.Param("id"), userID.(string), models.GoalRole.CanManage)
+	if goal == nil {
+		return
+	}
+
+	member := &models.GoalMember{GoalID: goal.ID, UserID: c.Param("user_id"), Role: req.Role}
+	err := h.DB.UpdateGoalMember(member)
+	if errors.Is(err, database.ErrMemberNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member"})
+		return
+	}
+
+	member, err = h.DB.GetGoalMember(goal.ID, member.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve member"})
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMemberHandler stops sharing a goal with a member. The owner can
+// remove anyone; members can remove themselves (leave the goal).
+// Their past contributions stay in the ledger.
+func (h *Handler) RemoveMemberHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	memberID := c.Param("user_id")
+	allowed := models.GoalRole.CanManage
+	if memberID == userID.(string) {
+		allowed = models.GoalRole.CanView
+	}
+	goal, _ := h.authorizeGoal(c, c.Param("id"), userID.(string), allowed)
+	if goal == nil {
+		return
+	}
+
+	err := h.DB.RemoveGoalMember(goal.ID, memberID)
+	if errors.Is(err, database.ErrMemberNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// CreateInvitationHandler invites someone by email to join a goal as a
+// contributor or viewer. Only the owner may invite. The email doesn't need
+// an account yet.
+func (h *Handler) CreateInvitationHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.InvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if fields := bindingFieldErrors(err); fields != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fields})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, _ := h.authorizeGoal(c, c.Param("id"), userID.(string), models.GoalRole.CanManage)
+	if goal == nil {
+		return
+	}
+
+	// Inviting someone who already has access would change nothing
+	email := models.NormalizeEmail(req.Email)
+	invitee, err := h.DB.GetUserByEmail(email)
+	switch {
+	case errors.Is(err, database.ErrUserNotFound):
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		return
+	case invitee.ID == goal.UserID:
+		c.JSON(http.StatusConflict, gin.H{"error": "The owner can't be invited to their own goal"})
+		return
+	default:
+		member, err := h.goalMembership(goal.ID, invitee.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve member"})
+			return
+		}
+		if member != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "This user is already a member of the goal"})
+			return
+		}
+	}
+
+	invitation := &models.Invitation{
+		ID:        uuid.New().String(),
+		GoalID:    goal.ID,
+		Email:     email,
+		Role:      req.Role,
+		InvitedBy: userID.(string),
+		Status:    models.InvitationPending,
+		CreatedAt: time.Now(),
+	}
+	err = h.DB.CreateInvitation(invitation)
+	switch {
+	case errors.Is(err, database.ErrGoalNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		return
+	case errors.Is(err, database.ErrInvitationExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "This email already has a pending invitation"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ListGoalInvitationsHandler lists a goal's pending invitations (owner only).
+func (h *Handler) ListGoalInvitationsHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	goal, _ := h.authorizeGoal(c, c.Param("id"), userID.(string), models.GoalRole.CanManage)
+	if goal == nil {
+		return
+	}
+
+	invitations, err := h.DB.ListGoalInvitations(goal.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invitations"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations, "count": len(invitations)})
+}
+
+// RevokeInvitationHandler deletes one of a goal's invitations (owner only).
+func (h *Handler) RevokeInvitationHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	goal, _ := h.authorizeGoal(c, c.Param("id"), userID.(string), models.GoalRole.CanManage)
+	if goal == nil {
+		return
+	}
+
+	invitation, err := h.DB.GetInvitation(c.Param("invitation_id"))
+	if errors.Is(err, database.ErrInvitationNotFound) || (err == nil && invitation.GoalID != goal.ID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invitation"})
+		return
+	}
+
+	if err := h.DB.DeleteInvitation(invitation.ID); err != nil && !errors.Is(err, database.ErrInvitationNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// ListMyInvitationsHandler lists the pending invitations sent to the
+// authenticated user's email.
+func (h *Handler) ListMyInvitationsHandler(c *gin.Context) {
+	user := h.currentUser(c)
+	if user == nil {
+		return
+	}
+
+	invitations, err := h.DB.ListInvitationsByEmail(models.NormalizeEmail(user.Email))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invitations"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations, "count": len(invitations)})
+}
+
+// AcceptInvitationHandler makes the authenticated user a member of the goal
+// they were invited to, with the invitation's role.
+func (h *Handler) AcceptInvitationHandler(c *gin.Context) {
+	h.respondToInvitation(c, true)
+}
+
+// DeclineInvitationHandler turns an invitation down.
+func (h *Handler) DeclineInvitationHandler(c *gin.Context) {
+	h.respondToInvitation(c, false)
+}
+
+// respondToInvitation accepts or declines the invitation in the URL on
+// behalf of the authenticated user, who must be the one it was sent to.
+func (h *Handler) respondToInvitation(c *gin.Context, accept bool) {
+	user := h.currentUser(c)
+	if user == nil {
+		return
+	}
+
+	// Invitations for someone else's email look the same as missing ones,
+	// so the IDs can't be probed
+	invitation, err := h.DB.GetInvitation(c.Param("id"))
+	if errors.Is(err, database.ErrInvitationNotFound) || (err == nil && invitation.Email != models.NormalizeEmail(user.Email)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invitation"})
+		return
+	}
+
+	invitation, err = h.DB.RespondToInvitation(invitation.ID, user.ID, accept, time.Now())
+	switch {
+	case errors.Is(err, database.ErrInvitationNotFound), errors.Is(err, database.ErrGoalNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	case errors.Is(err, database.ErrInvitationClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": "This invitation was already answered"})
+		return
+	case errors.Is(err, database.ErrInviteeIsOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": "The owner can't be invited to their own goal"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to answer invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invitation)
+}
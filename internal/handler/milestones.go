@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"go-api-server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// buildMilestones turns milestone definitions from a request into milestones
+// for a goal in currency. A milestone that matches one in existing (same
+// percent or amount) keeps the time it was reached. Problems are reported per
+// milestone as "milestones[i]".
+func buildMilestones(reqs []models.MilestoneRequest, currency string, existing []models.Milestone) ([]models.Milestone, fieldErrors) {
+	fields := fieldErrors{}
+	milestones := []models.Milestone{}
+
+	for i, req := range reqs {
+		key := fmt.Sprintf("milestones[%d]", i)
+		if (req.Percent == 0) == (req.Amount == nil) {
+			fields[key] = "must set exactly one of percent and amount"
+			continue
+		}
+
+		milestone := models.Milestone{Percent: req.Percent, Label: req.Label}
+		if req.Amount != nil {
+			amount, err := req.Amount.Resolve(currency)
+			if err != nil {
+				fields[key] = err.Error()
+				continue
+			}
+			milestone.Amount = &amount
+		}
+
+		duplicate := false
+		for _, other := range milestones {
+			duplicate = duplicate || other.SameTarget(milestone)
+		}
+		if duplicate {
+			fields[key] = "duplicates another milestone"
+			continue
+		}
+
+		for _, old := range existing {
+			if old.SameTarget(milestone) {
+				milestone.ReachedAt = old.ReachedAt
+			}
+		}
+		milestones = append(milestones, milestone)
+	}
+
+	if len(fields) > 0 {
+		return nil, fields
+	}
+	return milestones, nil
+}
+
+// ListMilestonesHandler returns a goal's milestones, lowest threshold first,
+// with when each was reached, and how many have been reached so far.
+func (h *Handler) ListMilestonesHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	goal, _ := h.authorizeGoal(c, c.Param("id"), userID.(string), models.GoalRole.CanView)
+	if goal == nil {
+		return
+	}
+
+	milestones := goal.Milestones
+	if milestones == nil {
+		milestones = []models.Milestone{}
+	}
+	reached := 0
+	for _, milestone := range milestones {
+		if milestone.ReachedAt != nil {
+			reached++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"goal_id":    goal.ID,
+		"milestones": milestones,
+		"reached":    reached,
+		"total":      len(milestones),
+	})
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-api-server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListGoalPeriodsHandler returns a page of a recurring goal's archived
+// periods, newest first, each with its final amount and whether it succeeded.
+// Query parameters: limit (default 20, max 100) and offset (default 0).
+func (h *Handler) ListGoalPeriodsHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	limit, offset, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goalID := c.Param("id")
+	goal, _ := h.authorizeGoal(c, goalID, userID.(string), models.GoalRole.CanView)
+	if goal == nil {
+		return
+	}
+
+	periods, total, err := h.DB.ListGoalPeriods(goalID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve periods"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.GoalPeriodPage{
+		Periods: periods,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	})
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"go-api-server/internal/database"
+	"go-api-server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// changeGoalStatus applies change (e.g. Goal.Pause) to the goal in the URL
+// and saves it in one atomic step. Only the owner may change a goal's status,
+// and If-Match is honored like for the other mutations. Deleted goals are
+// not found unless deleted is true (to restore them).
+// It returns the goal as saved, or nil once it has written an error response.
+func (h *Handler) changeGoalStatus(c *gin.Context, deleted bool, change func(goal *models.Goal, now time.Time) error) *models.Goal {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil
+	}
+
+	var stale *models.Goal
+	goal, err := h.DB.UpdateGoalFunc(c.Param("id"), func(goal *models.Goal) error {
+		if goal.Deleted() && !deleted {
+			return database.ErrGoalNotFound
+		}
+		if !models.RoleOf(goal, userID.(string), nil).CanManage() {
+			return errForbidden
+		}
+		if !ifMatch(c, goal) {
+			stale = goal
+			return errPreconditionFailed
+		}
+		return change(goal, time.Now())
+	})
+
+	switch {
+	case errors.Is(err, database.ErrGoalNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		return nil
+	case errors.Is(err, errForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return nil
+	case errors.Is(err, errPreconditionFailed):
+		respondPreconditionFailed(c, stale)
+		return nil
+	case errors.Is(err, models.ErrStatusTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return nil
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update goal"})
+		return nil
+	}
+
+	setGoalETag(c, goal)
+	return goal
+}
+
+// PauseGoalHandler freezes an active goal: no deposits or withdrawals, and a
+// recurring goal doesn't roll over, until it is resumed.
+func (h *Handler) PauseGoalHandler(c *gin.Context) {
+	if goal := h.changeGoalStatus(c, false, (*models.Goal).Pause); goal != nil {
+		c.JSON(http.StatusOK, goal)
+	}
+}
+
+// ResumeGoalHandler makes a paused goal active again. Its end date moves back
+// by as long as it was paused.
+func (h *Handler) ResumeGoalHandler(c *gin.Context) {
+	if goal := h.changeGoalStatus(c, false, (*models.Goal).Resume); goal != nil {
+		c.JSON(http.StatusOK, goal)
+	}
+}
+
+// ArchiveGoalHandler sets an active or paused goal aside. Archived goals are
+// frozen and left out of GET /goals unless ?status=archived is given.
+func (h *Handler) ArchiveGoalHandler(c *gin.Context) {
+	if goal := h.changeGoalStatus(c, false, (*models.Goal).Archive); goal != nil {
+		c.JSON(http.StatusOK, goal)
+	}
+}
+
+// UnarchiveGoalHandler brings an archived goal back, paused if it was paused
+// when it was archived and active otherwise.
+func (h *Handler) UnarchiveGoalHandler(c *gin.Context) {
+	unarchive := func(goal *models.Goal, now time.Time) error {
+		return goal.Unarchive()
+	}
+	if goal := h.changeGoalStatus(c, false, unarchive); goal != nil {
+		c.JSON(http.StatusOK, goal)
+	}
+}
+
+// RestoreGoalHandler undeletes a goal that hasn't been purged yet
+// (see GOAL_RETENTION). The goal comes back with the status it had.
+func (h *Handler) RestoreGoalHandler(c *gin.Context) {
+	restore := func(goal *models.Goal, now time.Time) error {
+		return goal.Restore()
+	}
+	if goal := h.changeGoalStatus(c, true, restore); goal != nil {
+		c.JSON(http.StatusOK, goal)
+	}
+}
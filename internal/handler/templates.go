@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-api-server/internal/database"
+	"go-api-server/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+// visibleTemplate loads the template with the given ID if userID may use it
+// (a built-in template or one of their own), writing a 404 or 403 response
+// and returning nil otherwise.
+func (h *Handler) visibleTemplate(c *gin.Context, userID, id string) *models.GoalTemplate {
+	template, err := h.DB.GetTemplate(id)
+	if errors.Is(err, database.ErrTemplateNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return nil
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve template"})
+		return nil
+	}
+	if !template.Builtin() && template.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return nil
+	}
+	return template
+}
+
+// templatesInCurrency shows templates to userID: amounts of built-in templates
+// are given in the user's default currency.
+func (h *Handler) templatesInCurrency(userID string, templates ...*models.GoalTemplate) ([]*models.GoalTemplate, error) {
+	user, err := h.goalOwner(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	shown := make([]*models.GoalTemplate, 0, len(templates))
+	for _, template := range templates {
+		template, err := template.InCurrency(user.PreferredCurrency())
+		if err != nil {
+			return nil, err
+		}
+		shown = append(shown, template)
+	}
+	return shown, nil
+}
+
+// ListTemplatesHandler returns the built-in templates followed by the
+// authenticated user's own.
+func (h *Handler) ListTemplatesHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	templates, err := h.DB.ListTemplates(userID.(string))
+	if err == nil {
+		templates, err = h.templatesInCurrency(userID.(string), templates...)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"templates": templates, "count": len(templates)})
+}
+
+// GetTemplateHandler returns a built-in template or one of the user's own.
+func (h *Handler) GetTemplateHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	template := h.visibleTemplate(c, userID.(string), c.Param("id"))
+	if template == nil {
+		return
+	}
+
+	shown, err := h.templatesInCurrency(userID.(string), template)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, shown[0])
+}
+
+// CreateTemplateHandler saves a private template made from a goal the user
+// can see: its title, target, duration, recurrence, milestones and tags.
+func (h *Handler) CreateTemplateHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if fields := bindingFieldErrors(err); fields != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fields})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+
+	goal, _ := h.authorizeGoal(c, req.GoalID, userID.(string), models.GoalRole.CanView)
+	if goal == nil {
+		return
+	}
+
+	template := models.NewGoalTemplate(uuid.New().String(), userID.(string), goal, req, time.Now())
+	if err := h.DB.CreateTemplate(template); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// DeleteTemplateHandler removes one of the user's own templates.
+// Built-in templates can't be deleted.
+func (h *Handler) DeleteTemplateHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	template := h.visibleTemplate(c, userID.(string), c.Param("id"))
+	if template == nil {
+		return
+	}
+	if template.Builtin() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Built-in templates can't be deleted"})
+		return
+	}
+
+	err := h.DB.DeleteTemplate(template.ID)
+	if errors.Is(err, database.ErrTemplateNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted successfully"})
+}
+
+// CreateGoalFromTemplateHandler creates a goal from a template.
+// The body is optional and takes the same fields as POST /goals; the fields
+// it sets replace the template's defaults (e.g. {"title": "Japan trip"}).
+func (h *Handler) CreateGoalFromTemplateHandler(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	template := h.visibleTemplate(c, userID.(string), c.Param("id"))
+	if template == nil {
+		return
+	}
+
+	// Decoding the body into the template's request only overwrites the
+	// fields the body contains; without a body the defaults are just validated
+	req := template.GoalRequest()
+	var err error
+	if c.Request.ContentLength != 0 {
+		err = c.ShouldBindJSON(&req)
+	} else {
+		err = binding.Validator.ValidateStruct(&req)
+	}
+	if err != nil {
+		if fields := bindingFieldErrors(err); fields != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "fields": fields})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.createGoal(c, userID.(string), req)
+}
@@ -1,14 +1,23 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
+	"go-api-server/internal/database"
+
 	"github.com/gin-gonic/gin"
 )
 
-func ListUsersHandler(c *gin.Context) {
+func (h *Handler) ListUsersHandler(c *gin.Context) {
 	  // GET all users from the db
-		users := DB.GetAllUsers()
+		users, err := h.DB.GetAllUsers()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve users",
+			})
+			return
+		}
 
 		// respond with the list of users and count
 		c.JSON(http.StatusOK, gin.H{
@@ -18,7 +27,7 @@ func ListUsersHandler(c *gin.Context) {
 }
 
 // Search endpoint only accounts for email for now
-func GetUserByEmailHandler(c *gin.Context) {
+func (h *Handler) GetUserByEmailHandler(c *gin.Context) {
 	// Get all possible query parameters
 	email := c.Query("email")
 
@@ -31,13 +40,19 @@ func GetUserByEmailHandler(c *gin.Context) {
 	}
 
 	// Search by email if provided
-	user, err := DB.GetUserByEmail(email)
-	if err != nil {
+	user, err := h.DB.GetUserByEmail(email)
+	if errors.Is(err, database.ErrUserNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "User not found",
 		})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
 
 	// Return the found user
 	c.JSON(http.StatusOK, gin.H{
@@ -45,16 +60,22 @@ func GetUserByEmailHandler(c *gin.Context) {
 	})
 }
 
-func GetUserByIDHandler(c *gin.Context) {
+func (h *Handler) GetUserByIDHandler(c *gin.Context) {
 	id := c.Param("id")
 
-	user, err := DB.GetUserByID(id)
-	if err != nil {
+	user, err := h.DB.GetUserByID(id)
+	if errors.Is(err, database.ErrUserNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "User not found",
 		})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve user",
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"user": user,
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go-api-server/internal/models"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// RegisterValidators teaches gin's request validator about our custom types.
+// It must run before any request is bound (SetupRouter calls it).
+func RegisterValidators() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// Report fields by their JSON names ("target_amount", not "TargetAmount"),
+	// or by their query parameter names for structs bound from the URL
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "" {
+			name = strings.SplitN(field.Tag.Get("form"), ",", 2)[0]
+		}
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	// "goalduration" accepts the durations listed in models.GoalDurations,
+	// so the list lives in one place instead of in every oneof tag
+	v.RegisterValidation("goalduration", func(fl validator.FieldLevel) bool {
+		duration, ok := fl.Field().Interface().(models.GoalDuration)
+		return ok && duration.Valid()
+	})
+
+	// Validate Money by its minor units, so tags like "required,gt=0"
+	// work on amounts the same way they did on float64
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if money, ok := field.Interface().(models.Money); ok {
+			return money.Minor
+		}
+		return nil
+	}, models.Money{})
+}
+
+// fieldErrors maps JSON field names to what is wrong with them.
+// Handlers render it as 400 Bad Request with a "fields" object, so clients
+// can show each message next to the right input.
+type fieldErrors map[string]string
+
+func (e fieldErrors) Error() string {
+	return "invalid fields"
+}
+
+// bindingFieldErrors converts the validator's errors from ShouldBindJSON into
+// fieldErrors. It returns nil for other errors (e.g. malformed JSON).
+func bindingFieldErrors(err error) fieldErrors {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	fields := fieldErrors{}
+	for _, fe := range validationErrors {
+		// The namespace is the field's path below the request struct, so
+		// nested fields are reported as e.g. "milestones[1].percent"
+		field := fe.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+
+		switch fe.Tag() {
+		case "required":
+			fields[field] = "is required"
+		case "oneof":
+			fields[field] = "must be one of: " + fe.Param()
+		case "goalduration":
+			fields[field] = "must be one of: " + goalDurationList()
+		default:
+			fields[field] = fmt.Sprintf("failed the %q rule", strings.TrimSpace(fe.Tag()+" "+fe.Param()))
+		}
+	}
+	return fields
+}
+
+// goalDurationList returns the supported goal durations separated by spaces,
+// the same way oneof errors list their options.
+func goalDurationList() string {
+	names := make([]string, len(models.GoalDurations))
+	for i, duration := range models.GoalDurations {
+		names[i] = string(duration)
+	}
+	return strings.Join(names, " ")
+}
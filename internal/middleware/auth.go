@@ -47,3 +47,20 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AdminMiddleware only lets admins through. It must run after AuthMiddleware,
+// which puts the authenticated email in the context.
+// isAdmin decides whether an email belongs to an admin.
+func AdminMiddleware(isAdmin func(email string) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email, _ := c.Get("email")
+		address, _ := email.(string)
+		if address == "" || !isAdmin(address) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
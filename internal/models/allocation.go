@@ -0,0 +1,114 @@
+package models
+
+import "errors"
+
+// AllocationStrategy is how POST /allocations splits an amount across goals.
+type AllocationStrategy string
+
+const (
+	// AllocateFixed gives each goal a fixed amount; whatever is left over
+	// stays unallocated
+	AllocateFixed AllocationStrategy = "fixed"
+
+	// AllocatePercent gives each goal a percentage of the amount. When the
+	// percentages add up to 100, the cents lost to rounding go to the first
+	// goals, so the whole amount is allocated
+	AllocatePercent AllocationStrategy = "percent"
+
+	// AllocatePriority fills the goals in the order given: each goal gets
+	// what it still needs to reach its target before the next one gets anything
+	AllocatePriority AllocationStrategy = "priority"
+)
+
+// MaxAllocationGoals is the most goals one allocation can be split across.
+const MaxAllocationGoals = 20
+
+// ErrAllocationTooLarge means the fixed amounts add up to more than the
+// amount being allocated.
+var ErrAllocationTooLarge = errors.New("the fixed amounts add up to more than the amount")
+
+// AllocationRequest is the body of POST /allocations: one deposit, e.g. a
+// slice of a paycheck, split across several goals.
+type AllocationRequest struct {
+	// Amount is the total to split; a plain number is in the goals' currency
+	Amount   Money              `json:"amount" binding:"required,gt=0"`
+	Strategy AllocationStrategy `json:"strategy" binding:"required,oneof=fixed percent priority"`
+
+	// Goals lists the goals, highest priority first for AllocatePriority
+	Goals []AllocationTarget `json:"goals" binding:"required,min=1,max=20,dive"`
+
+	// Note and Source are stored with each goal's contribution
+	Note   string `json:"note" binding:"max=500"`
+	Source string `json:"source" binding:"omitempty,max=32"`
+}
+
+// AllocationTarget is one goal of an allocation. Amount is required with
+// AllocateFixed and Percent with AllocatePercent; AllocatePriority uses neither.
+type AllocationTarget struct {
+	GoalID  string `json:"goal_id" binding:"required"`
+	Amount  *Money `json:"amount" binding:"omitempty,gt=0"`
+	Percent int    `json:"percent" binding:"omitempty,min=1,max=100"`
+}
+
+// Split works out each goal's share of total, in the order of goals (which
+// match r.Goals). total and the fixed amounts must already be in the goals'
+// currency. It returns the shares and what is left unallocated; shares can be
+// zero, e.g. for a goal that is already complete under AllocatePriority.
+func (r AllocationRequest) Split(total Money, amounts []Money, goals []*Goal) (shares []Money, rest Money, err error) {
+	currency := total.Currency
+	shares = make([]Money, len(goals))
+	left := total.Minor
+
+	switch r.Strategy {
+	case AllocateFixed:
+		for i := range goals {
+			left -= amounts[i].Minor
+			shares[i] = NewMoney(amounts[i].Minor, currency)
+		}
+		if left < 0 {
+			return nil, Money{}, ErrAllocationTooLarge
+		}
+	case AllocatePercent:
+		percent := 0
+		for i, target := range r.Goals {
+			// Round down, so the shares never add up to more than total
+			share := total.Minor * int64(target.Percent) / 100
+			shares[i] = NewMoney(share, currency)
+			left -= share
+			percent += target.Percent
+		}
+		for i := 0; percent == 100 && left > 0; i = (i + 1) % len(shares) {
+			shares[i].Minor++
+			left--
+		}
+	case AllocatePriority:
+		for i, goal := range goals {
+			need := goal.TargetAmount.Minor - goal.CurrentAmount.Minor
+			if need < 0 {
+				need = 0
+			}
+			if need > left {
+				need = left
+			}
+			shares[i] = NewMoney(need, currency)
+			left -= need
+		}
+	}
+	return shares, NewMoney(left, currency), nil
+}
+
+// AllocationResult is the response of POST /allocations.
+type AllocationResult struct {
+	Strategy AllocationStrategy `json:"strategy"`
+
+	// Amount is the total that was split: Allocated went into the goals and
+	// Unallocated is what was left over (e.g. once every goal was full)
+	Amount      Money `json:"amount"`
+	Allocated   Money `json:"allocated"`
+	Unallocated Money `json:"unallocated"`
+
+	// Contributions are the ledger entries recorded, one per goal that got
+	// money; Goals are all the goals of the allocation, as saved
+	Contributions []*Contribution `json:"contributions"`
+	Goals         []*Goal         `json:"goals"`
+}
@@ -0,0 +1,266 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Period sizes GET /me/analytics can group savings by.
+const (
+	AnalyticsWeek  = "week"
+	AnalyticsMonth = "month"
+)
+
+// defaultAnalyticsPeriods is how many periods are reported by default.
+const defaultAnalyticsPeriods = 12
+
+const (
+	// MovingAverageWindow is how far back the moving-average forecast looks
+	MovingAverageWindow = 28 * 24 * time.Hour
+
+	// maxForecast caps forecasts: a goal that would take longer than this at
+	// its current pace has no completion date
+	maxForecast = 100 * 365 * 24 * time.Hour
+)
+
+// AnalyticsQuery is the query string of GET /me/analytics,
+// e.g. /me/analytics?period=week&periods=8&currency=EUR.
+type AnalyticsQuery struct {
+	// Period groups the savings by week (Monday to Sunday) or by month (default)
+	Period string `form:"period" binding:"omitempty,oneof=week month"`
+
+	// Periods is how many periods to report, up to and including the current one
+	Periods int `form:"periods" binding:"omitempty,min=1,max=104"`
+
+	// Currency is the currency amounts are converted into (default: the
+	// user's default currency)
+	Currency string `form:"currency"`
+}
+
+// Normalize fills in the default period size and count.
+func (q *AnalyticsQuery) Normalize() {
+	if q.Period == "" {
+		q.Period = AnalyticsMonth
+	}
+	if q.Periods == 0 {
+		q.Periods = defaultAnalyticsPeriods
+	}
+}
+
+// periodStart returns the start of the week or month containing t, in t's location.
+func (q AnalyticsQuery) periodStart(t time.Time) time.Time {
+	if q.Period == AnalyticsWeek {
+		return weekStart(StartOfDay(t))
+	}
+	year, month, _ := t.Date()
+	return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+}
+
+// nextPeriod returns the start of the period after the one starting at start.
+func (q AnalyticsQuery) nextPeriod(start time.Time) time.Time {
+	if q.Period == AnalyticsWeek {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// SavedPeriods returns the last q.Periods periods up to and including the
+// one containing now, oldest first, with their bounds on the calendar of loc
+// and nothing saved yet.
+func (q AnalyticsQuery) SavedPeriods(now time.Time, loc *time.Location) []SavedPeriod {
+	start := q.periodStart(now.In(loc))
+	for i := 1; i < q.Periods; i++ {
+		if q.Period == AnalyticsWeek {
+			start = start.AddDate(0, 0, -7)
+		} else {
+			start = start.AddDate(0, -1, 0)
+		}
+	}
+
+	periods := make([]SavedPeriod, q.Periods)
+	for i := range periods {
+		end := q.nextPeriod(start)
+		periods[i] = SavedPeriod{Start: start, End: end}
+		start = end
+	}
+	return periods
+}
+
+// SavedPeriod is how much was saved across a user's goals in one week or month.
+type SavedPeriod struct {
+	// Start and End bound the period (End is the start of the next one)
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// Saved is the net amount added: deposits less withdrawals and corrections
+	Saved Money `json:"saved"`
+
+	// Deposits is how many deposits were made
+	Deposits int `json:"deposits"`
+}
+
+// Contains reports whether t falls within the period.
+func (p SavedPeriod) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && t.Before(p.End)
+}
+
+// Projection is one way of forecasting when a goal will reach its target:
+// saving DailyRate every day from now on.
+type Projection struct {
+	// DailyRate is the pace the projection assumes, in the goal's currency
+	DailyRate Money `json:"daily_rate"`
+
+	// CompletionDate is when the target would be reached at DailyRate; nil
+	// if the goal isn't growing (or would take more than a century)
+	CompletionDate *time.Time `json:"completion_date"`
+
+	// OnTime reports whether CompletionDate is no later than the goal's end date
+	OnTime bool `json:"on_time"`
+}
+
+// GoalForecast predicts when an active goal will be completed.
+type GoalForecast struct {
+	GoalID    string    `json:"goal_id"`
+	Title     string    `json:"title"`
+	Remaining Money     `json:"remaining"`
+	EndDate   time.Time `json:"end_date"`
+
+	// Linear assumes the goal keeps its average pace since the current
+	// period started
+	Linear Projection `json:"linear"`
+
+	// MovingAverage assumes the goal keeps the pace of its last
+	// MovingAverageWindow (or of its life so far, if it is younger)
+	MovingAverage Projection `json:"moving_average"`
+}
+
+// ForecastGoal projects when goal reaches its target, from its progress and
+// its ledger, as of now. ledger is the goal's whole history (see
+// Store.ContributionHistory); amounts are all in the goal's currency.
+func ForecastGoal(goal *Goal, ledger []*Contribution, now time.Time) GoalForecast {
+	currency := goal.Currency()
+	remaining := goal.TargetAmount.Minor - goal.CurrentAmount.Minor
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	forecast := GoalForecast{
+		GoalID:    goal.ID,
+		Title:     goal.Title,
+		Remaining: NewMoney(remaining, currency),
+		EndDate:   goal.EndDate,
+	}
+
+	// Linear: what was saved in the current period, spread over the days
+	// since it started (at least one, so a brand-new goal isn't extrapolated
+	// from a few minutes)
+	elapsed := days(now.Sub(goal.StartDate))
+	forecast.Linear = project(goal, remaining, float64(goal.CurrentAmount.Minor)/elapsed, now)
+
+	// Moving average: net savings over the recent window
+	since := now.Add(-MovingAverageWindow)
+	if goal.CreatedAt.After(since) {
+		since = goal.CreatedAt
+	}
+	var recent int64
+	for _, contribution := range ledger {
+		if !contribution.CreatedAt.Before(since) && !contribution.CreatedAt.After(now) {
+			recent += contribution.Amount.Minor
+		}
+	}
+	forecast.MovingAverage = project(goal, remaining, float64(recent)/days(now.Sub(since)), now)
+	return forecast
+}
+
+// days converts d into days, counting less than one day as one.
+func days(d time.Duration) float64 {
+	return math.Max(1, d.Hours()/24)
+}
+
+// project forecasts goal at a pace of rate minor units per day.
+func project(goal *Goal, remaining int64, rate float64, now time.Time) Projection {
+	projection := Projection{DailyRate: NewMoney(int64(math.Round(rate)), goal.Currency())}
+	if remaining == 0 {
+		projection.CompletionDate = &now
+		projection.OnTime = true
+		return projection
+	}
+	if rate <= 0 {
+		return projection
+	}
+
+	// Compare as a float first; a Duration that large would overflow
+	left := float64(remaining) / rate * float64(24*time.Hour)
+	if left > float64(maxForecast) {
+		return projection
+	}
+	completion := now.Add(time.Duration(left))
+	projection.CompletionDate = &completion
+	projection.OnTime = !completion.After(goal.EndDate)
+	return projection
+}
+
+// CompletionRate counts a user's finished goals: goals that are completed or
+// whose end date has passed, plus the archived periods of recurring goals
+// (a recurring goal's current period only counts once it is archived).
+// rate is the percentage of them that reached their target, rounded to two
+// decimals; nil if nothing has finished yet.
+func CompletionRate(goals []*Goal, periods []*GoalPeriod, now time.Time) (finished, completed int, rate *float64) {
+	for _, goal := range goals {
+		if goal.Recurring {
+			continue
+		}
+		if goal.Completed || !goal.EndDate.After(now) {
+			finished++
+			if goal.Completed {
+				completed++
+			}
+		}
+	}
+	for _, period := range periods {
+		finished++
+		if period.Succeeded {
+			completed++
+		}
+	}
+
+	if finished > 0 {
+		percent := math.Round(float64(completed)/float64(finished)*10000) / 100
+		rate = &percent
+	}
+	return finished, completed, rate
+}
+
+// Analytics is the response body of GET /me/analytics: how a user has been
+// saving across the goals they own, converted into one currency, and when
+// their active goals are expected to be completed.
+type Analytics struct {
+	// Currency is the currency the amounts (except forecasts) are expressed in
+	Currency string `json:"currency"`
+
+	// TimeZone is the zone whose calendar the periods follow
+	TimeZone string `json:"time_zone"`
+
+	// Period is "week" or "month"; SavedPerPeriod lists the periods oldest first
+	Period         string        `json:"period"`
+	SavedPerPeriod []SavedPeriod `json:"saved_per_period"`
+
+	// Deposits is the number of deposits ever made and AverageDeposit their
+	// average amount
+	Deposits       int   `json:"deposits"`
+	AverageDeposit Money `json:"average_deposit"`
+
+	// FinishedGoals, CompletedGoals and CompletionRate describe past goals
+	// (see CompletionRate)
+	FinishedGoals  int      `json:"finished_goals"`
+	CompletedGoals int      `json:"completed_goals"`
+	CompletionRate *float64 `json:"completion_rate"`
+
+	// Forecasts covers every active goal that isn't completed yet, in the
+	// goal's own currency
+	Forecasts []GoalForecast `json:"forecasts"`
+
+	// RatesUpdatedAt is when the exchange-rate table was last updated
+	// (omitted when no conversion was needed)
+	RatesUpdatedAt *time.Time `json:"rates_updated_at,omitempty"`
+}
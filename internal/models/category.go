@@ -0,0 +1,71 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxTags is the most tags a goal can have.
+const MaxTags = 20
+
+// Category groups a user's goals, e.g. "Travel" or "Emergency fund".
+// Each goal is in at most one category; deleting a category leaves its goals
+// uncategorized.
+type Category struct {
+	// ID is the unique identifier for the category
+	ID string `json:"id"`
+
+	// UserID is the owner; categories are private to each user
+	UserID string `json:"user_id"`
+
+	// Name is unique per user, ignoring case
+	Name string `json:"name"`
+
+	// Color is an optional hex color for clients, e.g. "#ff8800"
+	Color string `json:"color,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Clone returns a copy of the category, so changes to the copy don't affect the original.
+func (c *Category) Clone() *Category {
+	clone := *c
+	return &clone
+}
+
+// CategoryRequest is the body of POST /categories and PUT /categories/:id.
+type CategoryRequest struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Color string `json:"color" binding:"omitempty,hexcolor"`
+}
+
+// NormalizeTags cleans up tags sent by a client: surrounding spaces are
+// trimmed, tags are lowercased so "Travel" and "travel" are the same tag,
+// empty tags and duplicates are dropped and the rest sorted.
+// It never returns nil, so goals without tags have an empty list.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// HasTag reports whether the goal is tagged with tag (already normalized).
+func (g *Goal) HasTag(tag string) bool {
+	for _, t := range g.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// ContributionSourceManual is the source recorded when the client doesn't send one.
+const ContributionSourceManual = "manual"
+
+// ContributionKind says what kind of ledger entry a Contribution is.
+type ContributionKind string
+
+const (
+	// ContributionDeposit adds money to the goal (positive amount)
+	ContributionDeposit ContributionKind = "deposit"
+
+	// ContributionWithdrawal takes saved money out of the goal (negative amount)
+	ContributionWithdrawal ContributionKind = "withdrawal"
+
+	// ContributionCorrection fixes a mistake, e.g. a mistyped deposit (either sign)
+	ContributionCorrection ContributionKind = "correction"
+)
+
+// Contribution is one entry in a goal's ledger.
+// Every progress update (deposit, withdrawal or correction) is recorded as a
+// Contribution and never changed afterwards, so the ledger is a complete
+// history of who added or removed what, when and why.
+// A goal's CurrentAmount always equals the sum of its contributions (for a
+// recurring goal, of those recorded since it last rolled over).
+type Contribution struct {
+	// ID is the unique identifier for the contribution
+	ID string `json:"id"`
+
+	// GoalID is the goal the money was added to
+	GoalID string `json:"goal_id"`
+
+	// UserID is the user who made the contribution
+	UserID string `json:"user_id"`
+
+	// Kind is deposit, withdrawal or correction
+	Kind ContributionKind `json:"kind"`
+
+	// Amount is how much the goal's progress changed:
+	// positive for deposits, negative for withdrawals, either for corrections
+	// It is always in the goal's currency
+	Amount Money `json:"amount"`
+
+	// Note is an optional free-text description, e.g. "birthday money"
+	Note string `json:"note,omitempty"`
+
+	// Reason explains a withdrawal or correction (required for those kinds)
+	Reason string `json:"reason,omitempty"`
+
+	// Source describes where the contribution came from, e.g. "manual" or "ios"
+	Source string `json:"source"`
+
+	// CreatedAt is when the contribution was recorded
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Clone returns a copy of the contribution, so changes to the copy don't affect the original.
+func (c *Contribution) Clone() *Contribution {
+	clone := *c
+	return &clone
+}
+
+// ContributionPage is the response body of GET /goals/:id/contributions.
+type ContributionPage struct {
+	// Contributions is the requested page, newest first
+	Contributions []*Contribution `json:"contributions"`
+
+	// Total is the number of contributions the goal has across all pages
+	Total int `json:"total"`
+
+	// Limit and Offset echo the pagination parameters that were used
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
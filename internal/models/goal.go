@@ -5,31 +5,241 @@ import "time"
 type GoalDuration string
 
 const (
-    Weekly  GoalDuration = "weekly"
-    Monthly GoalDuration = "monthly"
-    Yearly  GoalDuration = "yearly"
+    Daily     GoalDuration = "daily"
+    Weekly    GoalDuration = "weekly"
+    Monthly   GoalDuration = "monthly"
+    Quarterly GoalDuration = "quarterly"
+    Yearly    GoalDuration = "yearly"
+
+    // Custom goals run between an explicit start and end date
+    Custom    GoalDuration = "custom"
 )
 
+// GoalDurations lists every supported duration, shortest first.
+var GoalDurations = []GoalDuration{Daily, Weekly, Monthly, Quarterly, Yearly, Custom}
+
+// Valid reports whether d is one of GoalDurations.
+func (d GoalDuration) Valid() bool {
+    for _, duration := range GoalDurations {
+        if d == duration {
+            return true
+        }
+    }
+    return false
+}
+
+// EndDate returns when a goal of this duration that starts at start ends.
+// Periods are made of whole calendar days in start's location: the goal ends
+// at midnight, one duration after the day it starts (a weekly goal started on
+// a Wednesday afternoon ends when the next Wednesday begins). Days are counted
+// on the local calendar, so a period spanning a daylight saving change is an
+// hour shorter or longer, but still ends at local midnight.
+// Custom goals have no fixed length, so for them start itself is returned.
+func (d GoalDuration) EndDate(start time.Time) time.Time {
+    day := StartOfDay(start)
+    switch d {
+    case Daily:
+        return day.AddDate(0, 0, 1)
+    case Weekly:
+        return day.AddDate(0, 0, 7)
+    case Monthly:
+        return day.AddDate(0, 1, 0)
+    case Quarterly:
+        return day.AddDate(0, 3, 0)
+    case Yearly:
+        return day.AddDate(1, 0, 0)
+    }
+    return start
+}
+
 type Goal struct {
     ID            string       `json:"id"`
     UserID        string       `json:"user_id"`
     Title         string       `json:"title"`
-    TargetAmount  float64      `json:"target_amount"`
-    CurrentAmount float64      `json:"current_amount"`
+    TargetAmount  Money        `json:"target_amount"`
+    CurrentAmount Money        `json:"current_amount"`
     Duration      GoalDuration `json:"duration"`
     StartDate     time.Time    `json:"start_date"`
     EndDate       time.Time    `json:"end_date"`
     Completed     bool         `json:"completed"`
     CompletedAt   *time.Time   `json:"completed_at,omitempty"`
     CreatedAt     time.Time    `json:"created_at"`
+
+    // Recurring goals start over when EndDate passes: the period is archived
+    // as a GoalPeriod and a new one begins (see RollOver).
+    // Period numbers the current period, starting at 1.
+    Recurring     bool         `json:"recurring"`
+    Period        int          `json:"period"`
+
+    // Milestones are intermediate targets, sorted by threshold and marked
+    // when progress reaches them (see UpdateMilestones)
+    Milestones    []Milestone  `json:"milestones"`
+
+    // Streaks count the days and weeks in a row with a deposit to this goal.
+    // They carry on across the periods of a recurring goal.
+    Streaks       Streaks      `json:"streaks"`
+
+    // CategoryID is the user's category the goal belongs to ("" for none)
+    // and Tags are free-form labels, lowercased and sorted (see NormalizeTags)
+    CategoryID    string       `json:"category_id,omitempty"`
+    Tags          []string     `json:"tags"`
+
+    // Status is active, paused or archived (see GoalStatus); PausedAt and
+    // ArchivedAt are when the goal was paused and archived
+    Status        GoalStatus   `json:"status"`
+    PausedAt      *time.Time   `json:"paused_at,omitempty"`
+    ArchivedAt    *time.Time   `json:"archived_at,omitempty"`
+
+    // DeletedAt is set once the goal is deleted; it can be restored until
+    // it is purged for good
+    DeletedAt     *time.Time   `json:"deleted_at,omitempty"`
+
+    // Version is incremented by the store on every update.
+    // It is exposed as the goal's ETag so clients can detect concurrent edits.
+    Version       int64        `json:"version"`
+}
+
+// Clone returns a deep copy of the goal.
+// Stores hand out clones so callers can never modify shared state by accident.
+func (g *Goal) Clone() *Goal {
+    clone := *g
+    if g.CompletedAt != nil {
+        completedAt := *g.CompletedAt
+        clone.CompletedAt = &completedAt
+    }
+    if g.PausedAt != nil {
+        pausedAt := *g.PausedAt
+        clone.PausedAt = &pausedAt
+    }
+    if g.ArchivedAt != nil {
+        archivedAt := *g.ArchivedAt
+        clone.ArchivedAt = &archivedAt
+    }
+    if g.DeletedAt != nil {
+        deletedAt := *g.DeletedAt
+        clone.DeletedAt = &deletedAt
+    }
+    clone.Milestones = cloneMilestones(g.Milestones)
+    if g.Tags != nil {
+        clone.Tags = append([]string{}, g.Tags...)
+    }
+    return &clone
+}
+
+// Currency returns the currency the goal is saved in (its target's currency).
+func (g *Goal) Currency() string {
+    return currencyOrDefault(g.TargetAmount.Currency)
+}
+
+// AddProgress adds amount (negative for withdrawals) to CurrentAmount and
+// keeps Completed in sync: the goal is marked completed (at the given time)
+// when it reaches its target, and reopened if it drops back below it.
+// Milestones reached along the way are marked too.
+// amount must be in the goal's currency.
+func (g *Goal) AddProgress(amount Money, at time.Time) error {
+    current, err := g.CurrentAmount.Add(amount)
+    if err != nil {
+        return err
+    }
+    g.CurrentAmount = current
+    return g.UpdateCompletion(at)
+}
+
+// UpdateCompletion re-evaluates Completed after CurrentAmount or TargetAmount
+// changed: the goal is completed (at the given time) once progress reaches the
+// target, and reopened (clearing CompletedAt) if it is below the target.
+// Milestones are re-evaluated as well.
+func (g *Goal) UpdateCompletion(at time.Time) error {
+    cmp, err := g.CurrentAmount.Cmp(g.TargetAmount)
+    if err != nil {
+        return err
+    }
+    g.UpdateMilestones(at)
+    switch {
+    case cmp >= 0 && !g.Completed:
+        g.Completed = true
+        g.CompletedAt = &at
+    case cmp < 0 && g.Completed:
+        g.Completed = false
+        g.CompletedAt = nil
+    }
+    return nil
 }
 
 type CreateGoalRequest struct {
     Title        string       `json:"title" binding:"required"`
-    TargetAmount float64      `json:"target_amount" binding:"required,gt=0"`
-    Duration     GoalDuration `json:"duration" binding:"required,oneof=weekly monthly yearly"`
+    // TargetAmount accepts a number, a decimal string or {"amount","currency"}
+    TargetAmount Money        `json:"target_amount" binding:"required,gt=0"`
+    Duration     GoalDuration `json:"duration" binding:"required,goalduration"`
+
+    // StartDate defaults to now and may be in the future.
+    // EndDate defaults to StartDate plus Duration; custom goals must set it.
+    StartDate    *time.Time   `json:"start_date"`
+    EndDate      *time.Time   `json:"end_date"`
+
+    // Currency is optional; it defaults to the target's currency if one was
+    // given, otherwise to the user's default currency
+    Currency     string       `json:"currency" binding:"omitempty,len=3"`
+
+    // Recurring goals roll over into a new period when the current one ends
+    Recurring    bool         `json:"recurring"`
+
+    // Milestones are optional intermediate targets (at most MaxMilestones)
+    Milestones   []MilestoneRequest `json:"milestones" binding:"omitempty,max=20,dive"`
+
+    // CategoryID is optional and must be one of the user's categories
+    CategoryID   string       `json:"category_id"`
+
+    // Tags are optional labels (at most MaxTags), e.g. ["travel", "2025"]
+    Tags         []string     `json:"tags" binding:"omitempty,max=20,dive,max=30"`
+}
+
+// UpdateGoalRequest is the body of PATCH /goals/:id.
+// Every field is optional; only the fields that are present are changed.
+type UpdateGoalRequest struct {
+    Title        *string       `json:"title" binding:"omitempty,max=200"`
+    TargetAmount *Money        `json:"target_amount" binding:"omitempty,gt=0"`
+    Duration     *GoalDuration `json:"duration" binding:"omitempty,goalduration"`
+
+    // StartDate and EndDate move the goal's period. If the start date or
+    // duration changes without an end date, the end date is recalculated
+    // from the start date (custom goals keep their end date)
+    StartDate    *time.Time    `json:"start_date"`
+    EndDate      *time.Time    `json:"end_date"`
+
+    // Recurring turns automatic rollover on or off
+    Recurring    *bool         `json:"recurring"`
+
+    // Milestones replaces the goal's milestones; milestones that are kept
+    // (same percent or amount) keep the time they were reached
+    Milestones   *[]MilestoneRequest `json:"milestones" binding:"omitempty,max=20,dive"`
+
+    // CategoryID moves the goal to another category ("" removes it from its category)
+    CategoryID   *string       `json:"category_id"`
+
+    // Tags replaces the goal's tags
+    Tags         *[]string     `json:"tags" binding:"omitempty,max=20,dive,max=30"`
 }
 
 type UpdateGoalProgressRequest struct {
-    Amount float64 `json:"amount" binding:"required,gt=0"`
+    Amount Money `json:"amount" binding:"required,gt=0"`
+
+    // Note and Source are stored with the contribution in the goal's ledger
+    Note   string `json:"note" binding:"max=500"`
+    Source string `json:"source" binding:"omitempty,max=32"`
+}
+
+// WithdrawGoalRequest takes money out of a goal (amount is positive).
+type WithdrawGoalRequest struct {
+    Amount Money  `json:"amount" binding:"required,gt=0"`
+    Reason string `json:"reason" binding:"required,max=500"`
+    Source string `json:"source" binding:"omitempty,max=32"`
+}
+
+// CorrectGoalRequest adjusts a goal's progress by a signed amount,
+// e.g. -50 to undo a deposit that was typed as 150 instead of 100.
+type CorrectGoalRequest struct {
+    Amount Money  `json:"amount" binding:"required"`
+    Reason string `json:"reason" binding:"required,max=500"`
+    Source string `json:"source" binding:"omitempty,max=32"`
 }
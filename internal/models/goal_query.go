@@ -0,0 +1,177 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Fields GET /goals can be sorted by.
+const (
+	GoalSortCreatedAt = "created_at"
+	GoalSortEndDate   = "end_date"
+	GoalSortProgress  = "progress"
+)
+
+// GoalStatusDeleted is the status filter of GET /goals that lists deleted
+// goals (which can still be restored) instead of live ones.
+const GoalStatusDeleted = "deleted"
+
+// GoalQuery selects one page of a user's goals.
+// The handler binds it from the query string of GET /goals, e.g.
+// /goals?completed=false&sort=end_date&limit=10&cursor=...
+type GoalQuery struct {
+	// UserID is the user whose goals are listed: the goals they own and the
+	// ones shared with them. Set by the handler, never from the URL
+	UserID string `form:"-"`
+
+	// Shared keeps only goals shared with the user (true) or only the
+	// user's own goals (false) when set
+	Shared *bool `form:"shared"`
+
+	// Status keeps only the goals with this status (active, paused or
+	// archived), or only deleted goals with GoalStatusDeleted. Without it,
+	// every goal except the archived and deleted ones is listed
+	Status string `form:"status" binding:"omitempty,oneof=active paused archived deleted"`
+
+	// Completed keeps only completed (true) or open (false) goals when set
+	Completed *bool `form:"completed"`
+
+	// Duration keeps only goals with this duration when set
+	Duration GoalDuration `form:"duration" binding:"omitempty,goalduration"`
+
+	// Search keeps goals whose title contains it (case-insensitive)
+	Search string `form:"q" binding:"max=200"`
+
+	// Category keeps only the goals in the category with this ID when set
+	Category string `form:"category"`
+
+	// Tags keeps only goals that have every one of these tags
+	// (?tag=travel&tag=2025); the handler normalizes them
+	Tags []string `form:"tag" binding:"omitempty,max=20,dive,max=30"`
+
+	// Date ranges (RFC 3339); each bound is inclusive and optional
+	CreatedAfter  *time.Time `form:"created_after"`
+	CreatedBefore *time.Time `form:"created_before"`
+	EndAfter      *time.Time `form:"end_after"`
+	EndBefore     *time.Time `form:"end_before"`
+
+	// Sort is one of the GoalSort constants (default created_at)
+	Sort string `form:"sort" binding:"omitempty,oneof=created_at end_date progress"`
+
+	// Order is "asc" (default) or "desc"
+	Order string `form:"order" binding:"omitempty,oneof=asc desc"`
+
+	// Limit is the page size
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+
+	// Cursor is the next_cursor of the previous page ("" for the first page)
+	Cursor string `form:"cursor"`
+}
+
+// Descending reports whether the query sorts in descending order.
+func (q GoalQuery) Descending() bool {
+	return q.Order == "desc"
+}
+
+// Matches reports whether goal passes the query's filters
+// (stores that filter in memory use it; SQL stores use WHERE clauses).
+// Stores only pass it goals UserID owns or is a member of.
+func (q GoalQuery) Matches(goal *Goal) bool {
+	if q.Shared != nil && (goal.UserID != q.UserID) != *q.Shared {
+		return false
+	}
+	switch q.Status {
+	case "":
+		if goal.Deleted() || goal.Status == GoalArchived {
+			return false
+		}
+	case GoalStatusDeleted:
+		if !goal.Deleted() {
+			return false
+		}
+	default:
+		if goal.Deleted() || goal.Status != GoalStatus(q.Status) {
+			return false
+		}
+	}
+	if q.Completed != nil && goal.Completed != *q.Completed {
+		return false
+	}
+	if q.Duration != "" && goal.Duration != q.Duration {
+		return false
+	}
+	if q.Search != "" && !containsFold(goal.Title, q.Search) {
+		return false
+	}
+	if q.Category != "" && goal.CategoryID != q.Category {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !goal.HasTag(tag) {
+			return false
+		}
+	}
+	if q.CreatedAfter != nil && goal.CreatedAt.Before(*q.CreatedAfter) {
+		return false
+	}
+	if q.CreatedBefore != nil && goal.CreatedAt.After(*q.CreatedBefore) {
+		return false
+	}
+	if q.EndAfter != nil && goal.EndDate.Before(*q.EndAfter) {
+		return false
+	}
+	if q.EndBefore != nil && goal.EndDate.After(*q.EndBefore) {
+		return false
+	}
+	return true
+}
+
+// containsFold reports whether substr is in s, ignoring case.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+const (
+	// progressExactLimit is the largest amount that can be multiplied by
+	// 10000 without overflowing an int64
+	progressExactLimit = math.MaxInt64 / 10000
+
+	// maxProgressBasisPoints is the progress of goals saved more than
+	// progressExactLimit times over
+	maxProgressBasisPoints = progressExactLimit * 10000
+)
+
+// ProgressBasisPoints returns how far the goal is towards its target in
+// hundredths of a percent (10000 = 100%), the value sort=progress orders by.
+// It uses integer maths so every store computes exactly the same number
+// (SQL stores mirror it branch for branch), and never overflows: amounts too
+// large to multiply by 10000 are divided first.
+func (g *Goal) ProgressBasisPoints() int64 {
+	current, target := g.CurrentAmount.Minor, g.TargetAmount.Minor
+	switch {
+	case target <= 0 || current <= 0:
+		return 0
+	case current <= progressExactLimit:
+		return current * 10000 / target
+	case target <= progressExactLimit:
+		// Split off the whole multiples of the target; the remainder is
+		// smaller than the target, so it can be multiplied
+		whole := current / target
+		if whole >= progressExactLimit {
+			return maxProgressBasisPoints
+		}
+		return whole*10000 + current%target*10000/target
+	default:
+		// Both are huge: scale the target down instead (off by at most one)
+		return current / (target / 10000)
+	}
+}
+
+// GoalPage is one page of goals returned by GET /goals.
+type GoalPage struct {
+	Goals []*Goal `json:"goals"`
+
+	// NextCursor fetches the following page; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestProgressBasisPointsDoesNotOverflow(t *testing.T) {
+	tests := []struct {
+		name            string
+		current, target int64
+	}{
+		{"half way", 50_00, 100_00},
+		{"no target", 50_00, 0},
+		{"largest exact product", progressExactLimit, 3},
+		{"just past the exact product", progressExactLimit + 1, 3},
+		{"huge balance, small target", math.MaxInt64, 100_00},
+		{"huge balance, tiny target", math.MaxInt64, 1},
+		{"huge balance, huge target", math.MaxInt64, math.MaxInt64 / 3},
+		{"huge balance, equal target", math.MaxInt64 - 1, math.MaxInt64 - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goal := &Goal{CurrentAmount: NewMoney(tt.current, "USD"), TargetAmount: NewMoney(tt.target, "USD")}
+			got := goal.ProgressBasisPoints()
+
+			var want int64
+			if tt.target > 0 {
+				exact := new(big.Int).Mul(big.NewInt(tt.current), big.NewInt(10000))
+				exact.Quo(exact, big.NewInt(tt.target))
+				want = maxProgressBasisPoints
+				if exact.IsInt64() && exact.Int64() < want {
+					want = exact.Int64()
+				}
+			}
+			// Only goals past the exact product with a huge target may be
+			// off, by one basis point
+			if got != want && !(got-want <= 1 && want-got <= 1 && tt.target > progressExactLimit) {
+				t.Errorf("ProgressBasisPoints(%d / %d) = %d, want %d", tt.current, tt.target, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// GoalRole is what a user may do with a goal that is shared with them.
+type GoalRole string
+
+const (
+	// RoleOwner is the goal's creator (Goal.UserID). Owners can do anything,
+	// including editing and deleting the goal and managing its members.
+	RoleOwner GoalRole = "owner"
+
+	// RoleContributor members can see the goal and add deposits to it
+	RoleContributor GoalRole = "contributor"
+
+	// RoleViewer members can only see the goal, its ledger and its members
+	RoleViewer GoalRole = "viewer"
+)
+
+// CanView reports whether the role may read the goal ("" is no role at all).
+func (r GoalRole) CanView() bool {
+	return r == RoleOwner || r == RoleContributor || r == RoleViewer
+}
+
+// CanContribute reports whether the role may add deposits to the goal.
+func (r GoalRole) CanContribute() bool {
+	return r == RoleOwner || r == RoleContributor
+}
+
+// CanManage reports whether the role may edit or delete the goal, take money
+// out of it and manage its members and invitations.
+func (r GoalRole) CanManage() bool {
+	return r == RoleOwner
+}
+
+// GoalMember is a user a goal is shared with. The owner isn't stored as a
+// member: ownership comes from Goal.UserID.
+type GoalMember struct {
+	GoalID string   `json:"goal_id"`
+	UserID string   `json:"user_id"`
+	Role   GoalRole `json:"role"`
+
+	// JoinedAt is when the user accepted the invitation
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// Clone returns a copy of the member, so changes to the copy don't affect the original.
+func (m *GoalMember) Clone() *GoalMember {
+	clone := *m
+	return &clone
+}
+
+// RoleOf returns the role userID has on goal, given its membership (nil if
+// the user isn't a member): RoleOwner for the owner, else the member's role,
+// else "" (no access).
+func RoleOf(goal *Goal, userID string, member *GoalMember) GoalRole {
+	if goal.UserID == userID {
+		return RoleOwner
+	}
+	if member != nil && member.UserID == userID && member.GoalID == goal.ID {
+		return member.Role
+	}
+	return ""
+}
+
+// InvitationStatus is where an invitation stands.
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationDeclined InvitationStatus = "declined"
+)
+
+// Invitation asks the user with Email to join a goal with Role.
+// The email doesn't need an account yet: the invitation shows up once a user
+// signs up with it.
+type Invitation struct {
+	ID     string `json:"id"`
+	GoalID string `json:"goal_id"`
+
+	// Email is the invitee's address, lowercased (see NormalizeEmail)
+	Email string   `json:"email"`
+	Role  GoalRole `json:"role"`
+
+	// InvitedBy is the ID of the owner who sent the invitation
+	InvitedBy string `json:"invited_by"`
+
+	Status      InvitationStatus `json:"status"`
+	CreatedAt   time.Time        `json:"created_at"`
+	RespondedAt *time.Time       `json:"responded_at,omitempty"`
+}
+
+// Clone returns a deep copy of the invitation.
+func (i *Invitation) Clone() *Invitation {
+	clone := *i
+	if i.RespondedAt != nil {
+		respondedAt := *i.RespondedAt
+		clone.RespondedAt = &respondedAt
+	}
+	return &clone
+}
+
+// NormalizeEmail lowercases and trims an email so accounts and invitations
+// match however the address was typed. Users are stored with it applied.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// InvitationRequest is the body of POST /goals/:id/invitations.
+type InvitationRequest struct {
+	Email string   `json:"email" binding:"required,email"`
+	Role  GoalRole `json:"role" binding:"required,oneof=contributor viewer"`
+}
+
+// UpdateMemberRequest is the body of PATCH /goals/:id/members/:user_id.
+type UpdateMemberRequest struct {
+	Role GoalRole `json:"role" binding:"required,oneof=contributor viewer"`
+}
+
+// MemberSummary describes one person on GET /goals/:id/members: the owner or
+// a member, with how much they have put into the goal.
+type MemberSummary struct {
+	UserID string   `json:"user_id"`
+	Email  string   `json:"email,omitempty"`
+	Role   GoalRole `json:"role"`
+
+	// JoinedAt is omitted for the owner
+	JoinedAt *time.Time `json:"joined_at,omitempty"`
+
+	// Contributed is the sum of the user's deposits, withdrawals and
+	// corrections on this goal, across all of its periods
+	Contributed Money `json:"contributed"`
+}
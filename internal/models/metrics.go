@@ -0,0 +1,109 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// GoalMetrics are values derived from a goal at a point in time.
+// They are computed on read and never stored.
+type GoalMetrics struct {
+	// PercentComplete is CurrentAmount / TargetAmount * 100, rounded to two
+	// decimals (it can exceed 100 when a goal is over-funded)
+	PercentComplete float64 `json:"percent_complete"`
+
+	// Remaining is how much is still needed to reach the target (never negative)
+	Remaining Money `json:"remaining"`
+
+	// DaysLeft is the number of days until EndDate, rounded up (0 once it has passed)
+	DaysLeft int `json:"days_left"`
+
+	// RequiredDailyPace and RequiredWeeklyPace are how much must be saved per
+	// day / per week from now on to finish on time (rounded up to the minor unit)
+	RequiredDailyPace  Money `json:"required_daily_pace"`
+	RequiredWeeklyPace Money `json:"required_weekly_pace"`
+
+	// ExpectedAmount is how much would be saved by now if progress were spread
+	// evenly between StartDate and EndDate
+	ExpectedAmount Money `json:"expected_amount"`
+
+	// OnTrack is true if the goal is completed or at least ExpectedAmount is saved
+	OnTrack bool `json:"on_track"`
+
+	// Overdue is true if EndDate has passed without the goal being completed
+	Overdue bool `json:"overdue"`
+
+	// Started is false for goals whose StartDate is still in the future
+	Started bool `json:"started"`
+}
+
+// GoalDetails is the response body of GET /goals/:id:
+// the goal's own fields plus its metrics.
+type GoalDetails struct {
+	*Goal
+	Metrics GoalMetrics `json:"metrics"`
+}
+
+// ComputeMetrics derives the progress metrics of goal as of now.
+func ComputeMetrics(goal *Goal, now time.Time) GoalMetrics {
+	currency := goal.Currency()
+	target := goal.TargetAmount.Minor
+	current := goal.CurrentAmount.Minor
+
+	metrics := GoalMetrics{
+		Remaining:          NewMoney(0, currency),
+		RequiredDailyPace:  NewMoney(0, currency),
+		RequiredWeeklyPace: NewMoney(0, currency),
+	}
+
+	if target > 0 {
+		metrics.PercentComplete = math.Round(float64(current)/float64(target)*10000) / 100
+	}
+	if remaining := target - current; remaining > 0 {
+		metrics.Remaining.Minor = remaining
+	}
+
+	if left := goal.EndDate.Sub(now); left > 0 {
+		metrics.DaysLeft = int(math.Ceil(left.Hours() / 24))
+	}
+
+	// Pace: spread what is left over the remaining days (or weeks) of the
+	// period; a goal that hasn't started yet is paced from its StartDate.
+	// With less than a week left, the weekly pace is simply everything remaining.
+	paceDays := int64(metrics.DaysLeft)
+	if goal.StartDate.After(now) {
+		paceDays = int64(math.Ceil(goal.EndDate.Sub(goal.StartDate).Hours() / 24))
+	}
+	remaining := metrics.Remaining.Minor
+	switch {
+	case remaining == 0:
+	case paceDays <= 0:
+		metrics.RequiredDailyPace.Minor = remaining
+		metrics.RequiredWeeklyPace.Minor = remaining
+	default:
+		days := paceDays
+		metrics.RequiredDailyPace.Minor = divCeil(remaining, days)
+		metrics.RequiredWeeklyPace.Minor = divCeil(remaining*7, days)
+		if metrics.RequiredWeeklyPace.Minor > remaining {
+			metrics.RequiredWeeklyPace.Minor = remaining
+		}
+	}
+
+	// Expected progress grows linearly from 0 at StartDate to the target at EndDate
+	fraction := 1.0
+	if total := goal.EndDate.Sub(goal.StartDate); total > 0 {
+		fraction = float64(now.Sub(goal.StartDate)) / float64(total)
+		fraction = math.Max(0, math.Min(1, fraction))
+	}
+	metrics.ExpectedAmount = NewMoney(int64(math.Ceil(float64(target)*fraction)), currency)
+
+	metrics.Started = !now.Before(goal.StartDate)
+	metrics.Overdue = !goal.Completed && metrics.DaysLeft == 0
+	metrics.OnTrack = goal.Completed || current >= metrics.ExpectedAmount.Minor
+	return metrics
+}
+
+// divCeil divides a by b (both positive), rounding up.
+func divCeil(a, b int64) int64 {
+	return (a + b - 1) / b
+}
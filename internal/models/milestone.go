@@ -0,0 +1,113 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// MaxMilestones is the most milestones a goal can have.
+const MaxMilestones = 20
+
+// Milestone is an intermediate target on the way to a goal: either a
+// percentage of the target amount (e.g. 25%) or a fixed amount.
+// Milestones are checked on every progress update and remember when they
+// were first reached; a later withdrawal doesn't erase that.
+type Milestone struct {
+	// Percent of the target amount (1-100); 0 for fixed-amount milestones
+	Percent int `json:"percent,omitempty"`
+
+	// Amount is a fixed amount in the goal's currency; nil for percent milestones
+	Amount *Money `json:"amount,omitempty"`
+
+	// Label is an optional name, e.g. "Halfway there"
+	Label string `json:"label,omitempty"`
+
+	// Threshold is the amount at which the milestone is reached
+	// (recomputed whenever the target changes)
+	Threshold Money `json:"threshold"`
+
+	// ReachedAt is when progress first reached Threshold; nil until then
+	ReachedAt *time.Time `json:"reached_at,omitempty"`
+}
+
+// MilestoneRequest defines a milestone in POST /goals and PATCH /goals/:id
+// (and in goal templates).
+// Exactly one of Percent and Amount must be set.
+type MilestoneRequest struct {
+	Percent int    `json:"percent,omitempty" binding:"omitempty,min=1,max=100"`
+	Amount  *Money `json:"amount,omitempty" binding:"omitempty,gt=0"`
+	Label   string `json:"label,omitempty" binding:"max=100"`
+}
+
+// clone returns a deep copy of the milestone.
+func (m Milestone) clone() Milestone {
+	if m.Amount != nil {
+		amount := *m.Amount
+		m.Amount = &amount
+	}
+	if m.ReachedAt != nil {
+		reachedAt := *m.ReachedAt
+		m.ReachedAt = &reachedAt
+	}
+	return m
+}
+
+// SameTarget reports whether m and other describe the same milestone
+// (the same percentage, or the same fixed amount).
+func (m Milestone) SameTarget(other Milestone) bool {
+	if m.Amount != nil || other.Amount != nil {
+		return m.Amount != nil && other.Amount != nil && m.Amount.Minor == other.Amount.Minor
+	}
+	return m.Percent == other.Percent
+}
+
+// cloneMilestones deep-copies a list of milestones.
+func cloneMilestones(milestones []Milestone) []Milestone {
+	if milestones == nil {
+		return nil
+	}
+	clones := make([]Milestone, len(milestones))
+	for i, milestone := range milestones {
+		clones[i] = milestone.clone()
+	}
+	return clones
+}
+
+// UpdateMilestones recomputes every milestone's threshold from the current
+// target, sorts the milestones by threshold, and marks those that progress
+// has reached as reached at the given time. It returns the milestones that
+// were reached just now (e.g. to tell the user).
+func (g *Goal) UpdateMilestones(at time.Time) []Milestone {
+	currency := g.Currency()
+	for i := range g.Milestones {
+		milestone := &g.Milestones[i]
+		if milestone.Amount != nil {
+			milestone.Threshold = NewMoney(milestone.Amount.Minor, currency)
+		} else {
+			// Round up, so a 50% milestone of 0.05 is reached at 0.03, not 0.02
+			percent := int64(milestone.Percent)
+			milestone.Threshold = NewMoney((g.TargetAmount.Minor*percent+99)/100, currency)
+		}
+	}
+	sort.SliceStable(g.Milestones, func(i, j int) bool {
+		return g.Milestones[i].Threshold.Minor < g.Milestones[j].Threshold.Minor
+	})
+
+	var reached []Milestone
+	for i := range g.Milestones {
+		milestone := &g.Milestones[i]
+		if milestone.ReachedAt == nil && g.CurrentAmount.Minor >= milestone.Threshold.Minor {
+			reachedAt := at
+			milestone.ReachedAt = &reachedAt
+			reached = append(reached, milestone.clone())
+		}
+	}
+	return reached
+}
+
+// resetMilestones clears ReachedAt on every milestone (for a new period).
+func (g *Goal) resetMilestones() {
+	for i := range g.Milestones {
+		g.Milestones[i].ReachedAt = nil
+	}
+}
@@ -0,0 +1,292 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DefaultCurrency is used for amounts sent without a currency code.
+const DefaultCurrency = "USD"
+
+// currencyExponents maps supported ISO 4217 currency codes to the number of
+// digits after the decimal point (2 for cents, 0 for currencies like KRW).
+var currencyExponents = map[string]int{
+	"AUD": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CNY": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"USD": 2,
+}
+
+// Errors returned by Money operations.
+var (
+	ErrUnknownCurrency  = errors.New("unsupported currency")
+	ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+	ErrTooPrecise       = errors.New("amount has more decimal places than the currency allows")
+
+	// ErrAmountOverflow means a sum or difference doesn't fit in an int64
+	// number of minor units
+	ErrAmountOverflow = errors.New("amount is too large")
+)
+
+// CurrencyExponent returns the number of minor-unit digits for code,
+// and false if the currency isn't supported.
+func CurrencyExponent(code string) (int, bool) {
+	exp, ok := currencyExponents[code]
+	return exp, ok
+}
+
+// NormalizeCurrency upper-cases code and reports whether it is supported.
+// An empty code means DefaultCurrency.
+func NormalizeCurrency(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		code = DefaultCurrency
+	}
+	_, ok := currencyExponents[code]
+	return code, ok
+}
+
+// Money is an exact amount of money: an integer number of minor units
+// (e.g. cents) plus the ISO 4217 currency code.
+//
+// Why not float64? Binary floating point can't represent most decimal
+// fractions exactly, so 0.1 + 0.2 != 0.3 and repeated deposits drift.
+// Integers add up exactly.
+//
+// In JSON, Money is written as {"amount":"100.50","currency":"USD"}.
+// For backward compatibility it also accepts a plain number (100.5) or a
+// decimal string ("100.50"); such amounts have no currency yet (Currency is
+// empty) and are scaled like DefaultCurrency until Resolve assigns one.
+type Money struct {
+	// Minor is the amount in the currency's smallest unit, e.g. 10050 for $100.50
+	Minor int64
+
+	// Currency is the ISO 4217 code, e.g. "USD"; empty means not specified
+	Currency string
+}
+
+// NewMoney creates an amount of minor units in the given currency.
+func NewMoney(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: currency}
+}
+
+// ParseMoney parses a decimal amount such as "100.50" in the given currency
+// ("" for an amount without a currency). The amount must fit the currency's
+// minor units exactly: "0.001" is an error for USD.
+func ParseMoney(amount, currency string) (Money, error) {
+	exp, ok := CurrencyExponent(currencyOrDefault(currency))
+	if !ok {
+		return Money{}, fmt.Errorf("%w %q", ErrUnknownCurrency, currency)
+	}
+
+	// big.Rat parses decimals (and exponents like 1e3) without rounding
+	value, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok {
+		return Money{}, fmt.Errorf("invalid amount %q", amount)
+	}
+	value.Mul(value, new(big.Rat).SetInt(pow10(exp)))
+	if !value.IsInt() {
+		return Money{}, fmt.Errorf("%w: %q", ErrTooPrecise, amount)
+	}
+	if !value.Num().IsInt64() {
+		return Money{}, fmt.Errorf("amount %q is too large", amount)
+	}
+	return Money{Minor: value.Num().Int64(), Currency: currency}, nil
+}
+
+// currencyOrDefault returns code, or DefaultCurrency if code is empty.
+func currencyOrDefault(code string) string {
+	if code == "" {
+		return DefaultCurrency
+	}
+	return code
+}
+
+// pow10 returns 10^exp as a big.Int.
+func pow10(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
+
+// exponent returns the number of minor-unit digits of m's currency.
+// Unknown currencies are rejected when parsing, so this falls back to 2.
+func (m Money) exponent() int {
+	if exp, ok := CurrencyExponent(currencyOrDefault(m.Currency)); ok {
+		return exp
+	}
+	return 2
+}
+
+// String formats the amount as a decimal without the currency, e.g. "100.50".
+func (m Money) String() string {
+	exp := m.exponent()
+	sign := ""
+	minor := m.Minor
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	digits := fmt.Sprintf("%0*d", exp+1, minor)
+	if exp == 0 {
+		return sign + digits
+	}
+	split := len(digits) - exp
+	return sign + digits[:split] + "." + digits[split:]
+}
+
+// Float64 returns the amount in major units as a float, e.g. 100.5.
+// Only use it for display-style calculations such as percentages,
+// never for amounts that are stored or added up.
+func (m Money) Float64() float64 {
+	value, _ := new(big.Rat).SetFrac(big.NewInt(m.Minor), pow10(m.exponent())).Float64()
+	return value
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+// IsNegative reports whether the amount is below zero.
+func (m Money) IsNegative() bool {
+	return m.Minor < 0
+}
+
+// Neg returns the amount with the opposite sign.
+func (m Money) Neg() Money {
+	return Money{Minor: -m.Minor, Currency: m.Currency}
+}
+
+// sameCurrency reports whether m and other can be added or compared.
+// An empty currency counts as DefaultCurrency.
+func (m Money) sameCurrency(other Money) bool {
+	return currencyOrDefault(m.Currency) == currencyOrDefault(other.Currency)
+}
+
+// Add returns m + other. Both amounts must be in the same currency.
+// It returns ErrAmountOverflow instead of wrapping around.
+func (m Money) Add(other Money) (Money, error) {
+	sum := m.Minor + other.Minor
+	// Overflow wraps around: adding a positive amount made the result
+	// smaller, or adding a negative one made it larger
+	overflow := (other.Minor > 0 && sum < m.Minor) || (other.Minor < 0 && sum > m.Minor)
+	return m.result(other, sum, overflow)
+}
+
+// Sub returns m - other. Both amounts must be in the same currency.
+// It returns ErrAmountOverflow instead of wrapping around.
+func (m Money) Sub(other Money) (Money, error) {
+	diff := m.Minor - other.Minor
+	overflow := (other.Minor < 0 && diff < m.Minor) || (other.Minor > 0 && diff > m.Minor)
+	return m.result(other, diff, overflow)
+}
+
+// result returns minor as the outcome of adding other to (or subtracting it
+// from) m, after checking their currencies match and nothing overflowed.
+func (m Money) result(other Money, minor int64, overflow bool) (Money, error) {
+	if !m.sameCurrency(other) {
+		return Money{}, ErrCurrencyMismatch
+	}
+	if overflow {
+		return Money{}, ErrAmountOverflow
+	}
+	currency := m.Currency
+	if currency == "" {
+		currency = other.Currency
+	}
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+// Cmp compares m and other: -1 if m < other, 0 if equal, +1 if m > other.
+// Both amounts must be in the same currency.
+func (m Money) Cmp(other Money) (int, error) {
+	if !m.sameCurrency(other) {
+		return 0, ErrCurrencyMismatch
+	}
+	switch {
+	case m.Minor < other.Minor:
+		return -1, nil
+	case m.Minor > other.Minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Resolve returns m in the given currency. An amount without a currency
+// (e.g. a plain number from JSON) is rescaled into that currency's minor
+// units; an amount that already has a different currency is an error.
+func (m Money) Resolve(currency string) (Money, error) {
+	if m.Currency != "" {
+		if m.Currency != currency {
+			return Money{}, ErrCurrencyMismatch
+		}
+		return m, nil
+	}
+	return ParseMoney(m.String(), currency)
+}
+
+// moneyJSON is the object form of Money in JSON.
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// MarshalJSON writes m as {"amount":"100.50","currency":"USD"}.
+// The amount is a string so clients can't lose precision by parsing it as a float.
+func (m Money) MarshalJSON() ([]byte, error) {
+	amount, err := json.Marshal(m.String())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(moneyJSON{Amount: amount, Currency: currencyOrDefault(m.Currency)})
+}
+
+// UnmarshalJSON accepts a number (100.5), a decimal string ("100.50") or the
+// object form ({"amount":"100.50","currency":"USD"}, where amount may also be
+// a number). Numbers are parsed from their text, so 0.1 is exactly 0.1.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	currency := ""
+	if len(data) > 0 && data[0] == '{' {
+		var obj moneyJSON
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		currency = strings.ToUpper(obj.Currency)
+		data = bytes.TrimSpace(obj.Amount)
+	}
+
+	var amount string
+	switch {
+	case len(data) > 0 && data[0] == '"':
+		if err := json.Unmarshal(data, &amount); err != nil {
+			return err
+		}
+	default:
+		var number json.Number
+		if err := json.Unmarshal(data, &number); err != nil {
+			return fmt.Errorf("amount must be a number or a decimal string")
+		}
+		amount = number.String()
+	}
+
+	parsed, err := ParseMoney(amount, currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
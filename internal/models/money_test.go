@@ -0,0 +1,48 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestMoneyArithmeticOverflow checks that sums and differences at the edges
+// of int64 return ErrAmountOverflow instead of wrapping around.
+func TestMoneyArithmeticOverflow(t *testing.T) {
+	usd := func(minor int64) Money { return NewMoney(minor, "USD") }
+	tests := []struct {
+		name    string
+		op      func(Money, Money) (Money, error)
+		a, b    int64
+		want    int64
+		wantErr error
+	}{
+		{name: "add up to max", op: Money.Add, a: math.MaxInt64 - 1, b: 1, want: math.MaxInt64},
+		{name: "add zero to max", op: Money.Add, a: math.MaxInt64, b: 0, want: math.MaxInt64},
+		{name: "add past max", op: Money.Add, a: math.MaxInt64, b: 1, wantErr: ErrAmountOverflow},
+		{name: "add two large amounts", op: Money.Add, a: math.MaxInt64/2 + 1, b: math.MaxInt64/2 + 1, wantErr: ErrAmountOverflow},
+		{name: "add past min", op: Money.Add, a: math.MinInt64, b: -1, wantErr: ErrAmountOverflow},
+		{name: "add opposite extremes", op: Money.Add, a: math.MaxInt64, b: math.MinInt64, want: -1},
+		{name: "sub down to min", op: Money.Sub, a: math.MinInt64 + 1, b: 1, want: math.MinInt64},
+		{name: "sub past min", op: Money.Sub, a: math.MinInt64, b: 1, wantErr: ErrAmountOverflow},
+		{name: "sub min from zero", op: Money.Sub, a: 0, b: math.MinInt64, wantErr: ErrAmountOverflow},
+		{name: "sub min from minus one", op: Money.Sub, a: -1, b: math.MinInt64, want: math.MaxInt64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op(usd(tt.a), usd(tt.b))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (got.Minor != tt.want || got.Currency != "USD") {
+				t.Errorf("got %d %s, want %d USD", got.Minor, got.Currency, tt.want)
+			}
+		})
+	}
+
+	// A currency mismatch is reported before any overflow
+	if _, err := usd(math.MaxInt64).Add(NewMoney(1, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("mismatched currencies: error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+}
@@ -0,0 +1,118 @@
+package models
+
+import "time"
+
+// GoalPeriod is a finished period of a recurring goal, archived when the
+// goal rolled over. It records how the period ended and never changes.
+type GoalPeriod struct {
+	// GoalID and Number identify the period: the goal's 1st, 2nd, ... period
+	GoalID string `json:"goal_id"`
+	Number int    `json:"number"`
+
+	// UserID is the owner of the goal
+	UserID string `json:"user_id"`
+
+	// StartDate and EndDate are the bounds of the period
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+
+	// TargetAmount is the target that applied during the period
+	TargetAmount Money `json:"target_amount"`
+
+	// FinalAmount is how much had been saved when the period was archived
+	FinalAmount Money `json:"final_amount"`
+
+	// Succeeded is true if the target was reached in this period
+	Succeeded   bool       `json:"succeeded"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+
+	// Milestones are the goal's milestones as they stood at the end of the
+	// period, including when each was reached
+	Milestones []Milestone `json:"milestones,omitempty"`
+
+	// ArchivedAt is when the rollover happened (shortly after EndDate)
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// Clone returns a deep copy of the period.
+func (p *GoalPeriod) Clone() *GoalPeriod {
+	clone := *p
+	if p.CompletedAt != nil {
+		completedAt := *p.CompletedAt
+		clone.CompletedAt = &completedAt
+	}
+	clone.Milestones = cloneMilestones(p.Milestones)
+	return &clone
+}
+
+// GoalPeriodPage is one page of a goal's archived periods, newest first.
+type GoalPeriodPage struct {
+	Periods []*GoalPeriod `json:"periods"`
+	Total   int           `json:"total"`
+	Limit   int           `json:"limit"`
+	Offset  int           `json:"offset"`
+}
+
+// RolloverDue reports whether the goal is recurring and active and its
+// current period has ended by now. Paused, archived and deleted goals don't
+// roll over.
+func (g *Goal) RolloverDue(now time.Time) bool {
+	return g.Recurring && g.Active() && !g.EndDate.After(now)
+}
+
+// RollOver archives every period of a recurring goal that has ended by now
+// and starts the next one: the new period begins where the old one ended,
+// lasts one Duration (custom goals: as long as the old one), and starts from
+// zero progress with no milestones reached.
+// If the server was down for several periods, each missed period is archived
+// separately (with nothing saved, so as failed). Period boundaries follow the
+// calendar of loc, the owner's time zone. The archived periods are returned
+// oldest first; nil means the goal wasn't due.
+func (g *Goal) RollOver(now time.Time, loc *time.Location) []*GoalPeriod {
+	// Goals created before periods were numbered are in their first period
+	if g.Period < 1 {
+		g.Period = 1
+	}
+
+	var periods []*GoalPeriod
+	for g.RolloverDue(now) {
+		next := g.nextEndDate(loc)
+		if !next.After(g.EndDate) {
+			// A period without a length would loop forever
+			break
+		}
+
+		periods = append(periods, &GoalPeriod{
+			GoalID:       g.ID,
+			Number:       g.Period,
+			UserID:       g.UserID,
+			StartDate:    g.StartDate,
+			EndDate:      g.EndDate,
+			TargetAmount: g.TargetAmount,
+			FinalAmount:  g.CurrentAmount,
+			Succeeded:    g.Completed,
+			CompletedAt:  g.CompletedAt,
+			Milestones:   cloneMilestones(g.Milestones),
+			ArchivedAt:   now,
+		})
+
+		g.Period++
+		g.StartDate = g.EndDate
+		g.EndDate = next
+		g.CurrentAmount = NewMoney(0, g.Currency())
+		g.Completed = false
+		g.CompletedAt = nil
+		g.resetMilestones()
+	}
+	return periods
+}
+
+// nextEndDate returns when the period after the current one ends: one
+// Duration after EndDate on the calendar of loc, or for custom goals, as long
+// again as the current period.
+func (g *Goal) nextEndDate(loc *time.Location) time.Time {
+	if g.Duration == Custom {
+		return g.EndDate.Add(g.EndDate.Sub(g.StartDate))
+	}
+	return g.Duration.EndDate(g.EndDate.In(loc))
+}
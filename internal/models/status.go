@@ -0,0 +1,114 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GoalStatus is where a goal is in its life cycle.
+// Deleting a goal doesn't change its status (see Goal.DeletedAt), so a
+// restored goal comes back as it was.
+type GoalStatus string
+
+const (
+	// GoalActive goals take deposits and withdrawals and roll over
+	GoalActive GoalStatus = "active"
+
+	// GoalPaused goals are frozen: their progress can't change and recurring
+	// goals don't roll over. Resuming moves the end date back by the time
+	// spent paused, so the pause doesn't eat into the goal's time
+	GoalPaused GoalStatus = "paused"
+
+	// GoalArchived goals are frozen like paused ones and left out of
+	// GET /goals unless asked for (?status=archived)
+	GoalArchived GoalStatus = "archived"
+)
+
+// ErrStatusTransition means a goal can't move to the requested status from
+// the one it is in, e.g. resuming a goal that isn't paused.
+var ErrStatusTransition = errors.New("invalid status change")
+
+// ErrGoalNotActive means the goal is paused or archived, so its progress
+// can't change.
+var ErrGoalNotActive = errors.New("the goal is not active")
+
+// Active reports whether the goal takes deposits and rolls over: it is
+// neither paused, archived nor deleted.
+func (g *Goal) Active() bool {
+	return g.Status == GoalActive && g.DeletedAt == nil
+}
+
+// Deleted reports whether the goal was deleted and is waiting to be purged.
+func (g *Goal) Deleted() bool {
+	return g.DeletedAt != nil
+}
+
+// Pause freezes an active goal at the given time.
+func (g *Goal) Pause(at time.Time) error {
+	if g.Status != GoalActive {
+		return fmt.Errorf("%w: only active goals can be paused (the goal is %s)", ErrStatusTransition, g.Status)
+	}
+	g.Status = GoalPaused
+	g.PausedAt = &at
+	return nil
+}
+
+// Resume makes a paused goal active again at the given time. The end date
+// moves back by as long as the goal was paused.
+func (g *Goal) Resume(at time.Time) error {
+	if g.Status != GoalPaused {
+		return fmt.Errorf("%w: only paused goals can be resumed (the goal is %s)", ErrStatusTransition, g.Status)
+	}
+	if g.PausedAt != nil && at.After(*g.PausedAt) {
+		g.EndDate = g.EndDate.Add(at.Sub(*g.PausedAt))
+	}
+	g.Status = GoalActive
+	g.PausedAt = nil
+	return nil
+}
+
+// Archive sets an active or paused goal aside at the given time.
+// A paused goal keeps PausedAt, so it is paused again once unarchived.
+func (g *Goal) Archive(at time.Time) error {
+	if g.Status == GoalArchived {
+		return fmt.Errorf("%w: the goal is already archived", ErrStatusTransition)
+	}
+	g.Status = GoalArchived
+	g.ArchivedAt = &at
+	return nil
+}
+
+// Unarchive brings an archived goal back: paused if it was paused when it
+// was archived (so resuming it later accounts for all the time it was
+// frozen), active otherwise.
+func (g *Goal) Unarchive() error {
+	if g.Status != GoalArchived {
+		return fmt.Errorf("%w: only archived goals can be unarchived (the goal is %s)", ErrStatusTransition, g.Status)
+	}
+	g.Status = GoalActive
+	if g.PausedAt != nil {
+		g.Status = GoalPaused
+	}
+	g.ArchivedAt = nil
+	return nil
+}
+
+// Delete marks the goal as deleted at the given time. It can be restored
+// until the purge job removes it for good.
+func (g *Goal) Delete(at time.Time) error {
+	if g.Deleted() {
+		return fmt.Errorf("%w: the goal is already deleted", ErrStatusTransition)
+	}
+	g.DeletedAt = &at
+	return nil
+}
+
+// Restore undoes Delete; the goal keeps the status it had.
+func (g *Goal) Restore() error {
+	if !g.Deleted() {
+		return fmt.Errorf("%w: the goal is not deleted", ErrStatusTransition)
+	}
+	g.DeletedAt = nil
+	return nil
+}
@@ -0,0 +1,107 @@
+package models
+
+import "time"
+
+// dateLayout formats the calendar dates streaks are counted in.
+const dateLayout = "2006-01-02"
+
+// Streak counts consecutive days (or weeks) with at least one deposit.
+type Streak struct {
+	// Current is the length of the streak that is still running: it counts
+	// while the latest deposit was made in this day/week or the one before
+	Current int `json:"current"`
+
+	// Longest is the longest streak ever reached
+	Longest int `json:"longest"`
+
+	// LastActive is the local date of the latest day with a deposit, or for
+	// weekly streaks the Monday of that week, e.g. "2024-03-18"
+	LastActive string `json:"last_active,omitempty"`
+}
+
+// Streaks are the daily and weekly saving streaks of a goal or a user.
+// Days and weeks (Monday to Sunday) follow the user's time zone.
+type Streaks struct {
+	Daily  Streak `json:"daily"`
+	Weekly Streak `json:"weekly"`
+}
+
+// Record counts a deposit made at in the streaks, with days and weeks on the
+// calendar of loc. Several deposits on the same day count once.
+func (s *Streaks) Record(at time.Time, loc *time.Location) {
+	day := calendarDate(at, loc)
+	week := weekStart(day)
+	s.Daily.record(day, day.AddDate(0, 0, -1))
+	s.Weekly.record(week, week.AddDate(0, 0, -7))
+}
+
+// AsOf returns the streaks as they stand at now: a streak whose last deposit
+// is older than the previous day (or week) in loc is broken, so its Current
+// is reported as 0. Longest is kept.
+func (s Streaks) AsOf(now time.Time, loc *time.Location) Streaks {
+	day := calendarDate(now, loc)
+	week := weekStart(day)
+	s.Daily = s.Daily.asOf(day, day.AddDate(0, 0, -1))
+	s.Weekly = s.Weekly.asOf(week, week.AddDate(0, 0, -7))
+	return s
+}
+
+// record extends the streak with a deposit in period; previous is the
+// period just before it.
+func (s *Streak) record(period, previous time.Time) {
+	date := period.Format(dateLayout)
+	// Dates compare correctly as strings; a deposit in a period before the
+	// latest one (e.g. after a time zone change) doesn't count again
+	if date <= s.LastActive {
+		return
+	}
+
+	if s.LastActive == previous.Format(dateLayout) {
+		s.Current++
+	} else {
+		s.Current = 1
+	}
+	if s.Current > s.Longest {
+		s.Longest = s.Current
+	}
+	s.LastActive = date
+}
+
+// asOf returns the streak with Current cleared if it was broken before period.
+func (s Streak) asOf(period, previous time.Time) Streak {
+	if s.LastActive != period.Format(dateLayout) && s.LastActive != previous.Format(dateLayout) {
+		s.Current = 0
+	}
+	return s
+}
+
+// calendarDate returns t's date on the calendar of loc, as midnight UTC so
+// that adding days can't be thrown off by daylight saving changes.
+func calendarDate(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// weekStart returns the Monday of the week containing date.
+func weekStart(date time.Time) time.Time {
+	return date.AddDate(0, 0, -(int(date.Weekday())+6)%7)
+}
+
+// GoalStreaks are the streaks of one goal, as listed by GET /me/streaks.
+type GoalStreaks struct {
+	GoalID string `json:"goal_id"`
+	Title  string `json:"title"`
+	Streaks
+}
+
+// StreakReport is the response body of GET /me/streaks.
+type StreakReport struct {
+	// TimeZone is the zone whose days and weeks the streaks are counted in
+	TimeZone string `json:"time_zone"`
+
+	// Streaks counts deposits to any of the user's goals
+	Streaks
+
+	// Goals lists the streaks of each goal
+	Goals []GoalStreaks `json:"goals"`
+}
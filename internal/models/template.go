@@ -0,0 +1,157 @@
+package models
+
+import "time"
+
+// GoalTemplate is a reusable starting point for a goal, e.g. "Emergency
+// fund": a default title, target, duration and milestones that
+// POST /goals/from-template/:id copies into a new goal.
+//
+// Built-in templates are shared by everyone and have no UserID; they are
+// seeded from the JSON file in the templates package. Users can also save
+// private templates made from one of their goals.
+type GoalTemplate struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id,omitempty"`
+
+	// Name and Description describe the template in the template list
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Title is the default title of goals made from the template
+	Title string `json:"title"`
+
+	// Currency is the currency of the template's amounts. It is "" for
+	// built-in templates, whose amounts are in each user's default currency;
+	// private templates keep the currency of the goal they were made from
+	Currency     string       `json:"currency,omitempty"`
+	TargetAmount Money        `json:"target_amount"`
+	Duration     GoalDuration `json:"duration"`
+	Recurring    bool         `json:"recurring"`
+
+	// Milestones are given the same way as in POST /goals
+	Milestones []MilestoneRequest `json:"milestones"`
+	Tags       []string           `json:"tags"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Builtin reports whether the template is a built-in one.
+func (t *GoalTemplate) Builtin() bool {
+	return t.UserID == ""
+}
+
+// Clone returns a deep copy of the template.
+func (t *GoalTemplate) Clone() *GoalTemplate {
+	clone := *t
+	if t.Milestones != nil {
+		clone.Milestones = make([]MilestoneRequest, len(t.Milestones))
+		for i, milestone := range t.Milestones {
+			if milestone.Amount != nil {
+				amount := *milestone.Amount
+				milestone.Amount = &amount
+			}
+			clone.Milestones[i] = milestone
+		}
+	}
+	if t.Tags != nil {
+		clone.Tags = append([]string{}, t.Tags...)
+	}
+	return &clone
+}
+
+// withoutCurrency returns a copy of the template whose amounts have no
+// currency if the template has none (amounts without a currency are stored
+// as DefaultCurrency, which they are scaled like).
+func (t *GoalTemplate) withoutCurrency() *GoalTemplate {
+	clone := t.Clone()
+	if t.Currency != "" {
+		return clone
+	}
+	clone.TargetAmount.Currency = ""
+	for _, milestone := range clone.Milestones {
+		if milestone.Amount != nil {
+			milestone.Amount.Currency = ""
+		}
+	}
+	return clone
+}
+
+// InCurrency returns a copy of the template with its amounts in the
+// template's currency, or in fallback (the user's default currency) for
+// templates without one. It is how a template is shown to a user.
+func (t *GoalTemplate) InCurrency(fallback string) (*GoalTemplate, error) {
+	clone := t.withoutCurrency()
+	if clone.Currency == "" {
+		clone.Currency = fallback
+	}
+
+	var err error
+	if clone.TargetAmount, err = clone.TargetAmount.Resolve(clone.Currency); err != nil {
+		return nil, err
+	}
+	for i := range clone.Milestones {
+		if amount := clone.Milestones[i].Amount; amount != nil {
+			resolved, err := amount.Resolve(clone.Currency)
+			if err != nil {
+				return nil, err
+			}
+			clone.Milestones[i].Amount = &resolved
+		}
+	}
+	return clone, nil
+}
+
+// GoalRequest returns the CreateGoalRequest the template stands for, which
+// the body of POST /goals/from-template/:id can then override. Amounts of
+// templates without a currency are left without one, so they end up in the
+// currency the request picks (or the user's default).
+func (t *GoalTemplate) GoalRequest() CreateGoalRequest {
+	clone := t.withoutCurrency()
+	return CreateGoalRequest{
+		Title:        clone.Title,
+		TargetAmount: clone.TargetAmount,
+		Duration:     clone.Duration,
+		Currency:     clone.Currency,
+		Recurring:    clone.Recurring,
+		Milestones:   clone.Milestones,
+		Tags:         clone.Tags,
+	}
+}
+
+// NewGoalTemplate makes a private template for userID out of goal. Milestones
+// keep their percentage or fixed amount, but not when they were reached.
+func NewGoalTemplate(id, userID string, goal *Goal, req CreateTemplateRequest, at time.Time) *GoalTemplate {
+	template := &GoalTemplate{
+		ID:           id,
+		UserID:       userID,
+		Name:         req.Name,
+		Description:  req.Description,
+		Title:        goal.Title,
+		Currency:     goal.Currency(),
+		TargetAmount: goal.TargetAmount,
+		Duration:     goal.Duration,
+		Recurring:    goal.Recurring,
+		Milestones:   []MilestoneRequest{},
+		Tags:         append([]string{}, goal.Tags...),
+		CreatedAt:    at,
+	}
+	if template.Name == "" {
+		template.Name = goal.Title
+	}
+	for _, milestone := range cloneMilestones(goal.Milestones) {
+		template.Milestones = append(template.Milestones, MilestoneRequest{
+			Percent: milestone.Percent,
+			Amount:  milestone.Amount,
+			Label:   milestone.Label,
+		})
+	}
+	return template
+}
+
+// CreateTemplateRequest is the body of POST /templates: the goal to copy and
+// an optional name (defaulting to the goal's title) and description.
+type CreateTemplateRequest struct {
+	GoalID      string `json:"goal_id" binding:"required"`
+	Name        string `json:"name" binding:"max=100"`
+	Description string `json:"description" binding:"max=500"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	// Embed the IANA time zone database in the binary, so zones like
+	// "Asia/Seoul" load even on hosts (or containers) without /usr/share/zoneinfo
+	_ "time/tzdata"
+)
+
+// DefaultTimeZone is used for users who haven't picked a time zone.
+const DefaultTimeZone = "UTC"
+
+// ErrUnknownTimeZone is returned for names that aren't IANA time zones.
+var ErrUnknownTimeZone = errors.New("unknown time zone")
+
+// LoadTimeZone returns the location for an IANA time zone name such as
+// "America/New_York". An empty name means DefaultTimeZone. "Local" is
+// rejected because it would depend on the server's configuration.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimeZone
+	}
+	if name == "Local" {
+		return nil, fmt.Errorf("%w %q", ErrUnknownTimeZone, name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownTimeZone, name)
+	}
+	return loc, nil
+}
+
+// StartOfDay returns midnight at the beginning of t's day in t's location.
+func StartOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
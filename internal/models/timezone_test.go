@@ -0,0 +1,126 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// TestPeriodBoundariesAcrossDST checks that end dates and rollovers land on
+// local midnight on days that are 23 or 25 hours long.
+func TestPeriodBoundariesAcrossDST(t *testing.T) {
+	tests := []struct {
+		name     string
+		zone     string
+		duration GoalDuration
+		start    string // local time
+		wantEnd  string // local midnight ending the first period
+		wantNext string // local midnight ending the period after it
+	}{
+		{
+			// Clocks go back at 02:00 on 2024-11-03: that day has 25 hours
+			name:     "new york fall-back daily",
+			zone:     "America/New_York",
+			duration: Daily,
+			start:    "2024-11-02 15:30",
+			wantEnd:  "2024-11-03 00:00",
+			wantNext: "2024-11-04 00:00",
+		},
+		{
+			name:     "new york fall-back weekly",
+			zone:     "America/New_York",
+			duration: Weekly,
+			start:    "2024-10-27 09:00",
+			wantEnd:  "2024-11-03 00:00",
+			wantNext: "2024-11-10 00:00",
+		},
+		{
+			name:     "new york fall-back monthly",
+			zone:     "America/New_York",
+			duration: Monthly,
+			start:    "2024-10-03 23:59",
+			wantEnd:  "2024-11-03 00:00",
+			wantNext: "2024-12-03 00:00",
+		},
+		{
+			// Clocks go forward at 02:00 on 2024-03-31: that day has 23 hours
+			name:     "berlin spring-forward daily",
+			zone:     "Europe/Berlin",
+			duration: Daily,
+			start:    "2024-03-30 12:00",
+			wantEnd:  "2024-03-31 00:00",
+			wantNext: "2024-04-01 00:00",
+		},
+		{
+			name:     "berlin spring-forward weekly",
+			zone:     "Europe/Berlin",
+			duration: Weekly,
+			start:    "2024-03-24 00:00",
+			wantEnd:  "2024-03-31 00:00",
+			wantNext: "2024-04-07 00:00",
+		},
+		{
+			name:     "berlin spring-forward yearly",
+			zone:     "Europe/Berlin",
+			duration: Yearly,
+			start:    "2023-03-31 08:00",
+			wantEnd:  "2024-03-31 00:00",
+			wantNext: "2025-03-31 00:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := LoadTimeZone(tt.zone)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parse := func(value string) time.Time {
+				parsed, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return parsed
+			}
+			start := parse(tt.start)
+
+			end := tt.duration.EndDate(start)
+			assertLocalMidnight(t, "end date", end, parse(tt.wantEnd), loc)
+
+			goal := &Goal{
+				Title:         "DST",
+				TargetAmount:  NewMoney(1000, "USD"),
+				CurrentAmount: NewMoney(0, "USD"),
+				Duration:      tt.duration,
+				StartDate:     start,
+				EndDate:       end,
+				Recurring:     true,
+				Period:        1,
+				Status:        GoalActive,
+			}
+			// Roll over an hour after the period ended, seen from UTC
+			periods := goal.RollOver(end.Add(time.Hour).UTC(), loc)
+			if len(periods) != 1 {
+				t.Fatalf("archived %d periods, want 1", len(periods))
+			}
+			if !periods[0].EndDate.Equal(end) {
+				t.Errorf("archived period ends %v, want %v", periods[0].EndDate, end)
+			}
+			if !goal.StartDate.Equal(end) {
+				t.Errorf("next period starts %v, want %v", goal.StartDate, end)
+			}
+			assertLocalMidnight(t, "next rollover", goal.EndDate, parse(tt.wantNext), loc)
+		})
+	}
+}
+
+// assertLocalMidnight fails unless got is the instant want and midnight in loc.
+func assertLocalMidnight(t *testing.T, what string, got, want time.Time, loc *time.Location) {
+	t.Helper()
+	if !got.Equal(want) {
+		t.Errorf("%s = %v, want %v", what, got.In(loc), want)
+	}
+	local := got.In(loc)
+	if local.Hour() != 0 || local.Minute() != 0 || local.Second() != 0 {
+		t.Errorf("%s = %v, not midnight in %s", what, local, loc)
+	}
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// CurrencyTotal sums a user's goals that are saved in one currency.
+type CurrencyTotal struct {
+	// Currency is the ISO 4217 code these goals are saved in
+	Currency string `json:"currency"`
+
+	// Goals is the number of goals in this currency
+	Goals int `json:"goals"`
+
+	// Saved and Target are the sums of CurrentAmount and TargetAmount
+	Saved  Money `json:"saved"`
+	Target Money `json:"target"`
+}
+
+// Totals is the response body of GET /me/totals: everything a user has saved
+// across all goals, converted into one currency.
+type Totals struct {
+	// Currency is the currency the totals are expressed in
+	Currency string `json:"currency"`
+
+	// Goals is the number of goals included
+	Goals int `json:"goals"`
+
+	// Saved and Target are the converted sums across all goals
+	Saved  Money `json:"saved"`
+	Target Money `json:"target"`
+
+	// ByCurrency lists the unconverted sums per original currency
+	ByCurrency []CurrencyTotal `json:"by_currency"`
+
+	// RatesUpdatedAt is when the exchange-rate table was last updated
+	// (omitted when no conversion was needed)
+	RatesUpdatedAt *time.Time `json:"rates_updated_at,omitempty"`
+
+	// GroupBy and Groups are set when the totals were requested per category
+	// or per tag (?group_by=category|tag)
+	GroupBy string        `json:"group_by,omitempty"`
+	Groups  []TotalsGroup `json:"groups,omitempty"`
+}
+
+// Values of ?group_by= on GET /me/totals.
+const (
+	GroupByCategory = "category"
+	GroupByTag      = "tag"
+)
+
+// TotalsGroup sums the goals of one category or tag, converted into the
+// totals' currency.
+type TotalsGroup struct {
+	// Key is the category ID or the tag; "" collects the goals without one
+	Key string `json:"key"`
+
+	// Name is the category's name or the tag
+	Name string `json:"name"`
+
+	Goals  int   `json:"goals"`
+	Saved  Money `json:"saved"`
+	Target Money `json:"target"`
+}
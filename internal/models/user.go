@@ -17,6 +17,18 @@ type User struct {
 	// We'll use bcrypt to hash passwords before storing
 	Password string `json:"-"` // json:"-" means this field won't be included in JSON responses
 	
+	// DefaultCurrency is the ISO 4217 code used for new goals and for totals
+	// converted across goals (see GET /me/totals), e.g. "USD"
+	DefaultCurrency string `json:"default_currency"`
+	
+	// TimeZone is the IANA name of the user's time zone, e.g. "Asia/Seoul"
+	// Goal periods start and end at midnight in this zone
+	TimeZone string `json:"time_zone"`
+	
+	// Streaks count the days and weeks in a row with a deposit to any of the
+	// user's goals. They are only changed through Store.UpdateUserStreaks
+	Streaks Streaks `json:"streaks"`
+	
 	// CreatedAt tracks when the user account was created
 	CreatedAt time.Time `json:"created_at"`
 	
@@ -24,6 +36,28 @@ type User struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Clone returns a copy of the user, so changes to the copy don't affect the original.
+func (u *User) Clone() *User {
+	clone := *u
+	return &clone
+}
+
+// PreferredCurrency returns the user's default currency,
+// or DefaultCurrency for accounts created before users could pick one.
+func (u *User) PreferredCurrency() string {
+	return currencyOrDefault(u.DefaultCurrency)
+}
+
+// Location returns the user's time zone, falling back to DefaultTimeZone
+// (UTC) for accounts without one or with a zone that no longer loads.
+func (u *User) Location() *time.Location {
+	loc, err := LoadTimeZone(u.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
 // SignupRequest represents the data required for user registration.
 // This is what we expect to receive in the request body for /signup
 type SignupRequest struct {
@@ -33,6 +67,22 @@ type SignupRequest struct {
 	// Password is the user's chosen password
 	// binding:"required,min=6" means this field is required and must be at least 6 characters
 	Password string `json:"password" binding:"required,min=6"`
+	
+	// DefaultCurrency is optional; it defaults to DefaultCurrency ("USD")
+	DefaultCurrency string `json:"default_currency" binding:"omitempty,len=3"`
+	
+	// TimeZone is optional; it defaults to DefaultTimeZone ("UTC")
+	TimeZone string `json:"time_zone" binding:"max=64"`
+}
+
+// UpdateMeRequest changes the authenticated user's settings (PATCH /me).
+// Fields left out of the request body are not changed.
+type UpdateMeRequest struct {
+	// DefaultCurrency is the ISO 4217 code for new goals and converted totals
+	DefaultCurrency *string `json:"default_currency" binding:"omitempty,len=3"`
+
+	// TimeZone is an IANA time zone name such as "Europe/Berlin"
+	TimeZone *string `json:"time_zone" binding:"omitempty,max=64"`
 }
 
 // LoginRequest represents the data required for user login.
@@ -58,7 +108,20 @@ type AuthResponse struct {
 // UserResponse represents user data that is safe to send to clients.
 // Note: We don't include the password field here for security
 type UserResponse struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
+	ID              string    `json:"id"`
+	Email           string    `json:"email"`
+	DefaultCurrency string    `json:"default_currency"`
+	TimeZone        string    `json:"time_zone"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// NewUserResponse builds the client-safe view of user.
+func NewUserResponse(user *User) UserResponse {
+	return UserResponse{
+		ID:              user.ID,
+		Email:           user.Email,
+		DefaultCurrency: user.DefaultCurrency,
+		TimeZone:        user.Location().String(),
+		CreatedAt:       user.CreatedAt,
+	}
 }
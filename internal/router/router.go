@@ -44,8 +44,12 @@ func PostHandler(c *gin.Context) {
 }
 
 // SetupRouter initializes and configures the Gin router with all routes.
+// The handler methods are bound to h, which carries the storage backend.
 // Returns a pointer to the configured gin.Engine instance.
-func SetupRouter() *gin.Engine {
+func SetupRouter(h *handler.Handler) *gin.Engine {
+    // Register validation for custom request types (e.g. models.Money)
+    handler.RegisterValidators()
+
     r := gin.Default() // Create a new Gin router with default middleware (logger and recovery)
 
     // Register a GET route at "/get" and associate it with GetHandler.
@@ -60,38 +64,97 @@ func SetupRouter() *gin.Engine {
     // POST /signup - Register a new user account
     // Expects: { "email": "user@example.com", "password": "password123" }
     // Returns: JWT token and user info
-    r.POST("/signup", handler.SignupHandler)
+    r.POST("/signup", h.SignupHandler)
 
     // POST /login - Authenticate an existing user
     // Expects: { "email": "user@example.com", "password": "password123" }
     // Returns: JWT token and user info
-    r.POST("/login", handler.LoginHandler)
+    r.POST("/login", h.LoginHandler)
 
     // POST /logout - Log out the current user
     // Expects: Authorization header with Bearer token
     // Returns: Success message
-    r.POST("/logout", handler.LogoutHandler)
+    r.POST("/logout", h.LogoutHandler)
 
     // GET /users - List all registered users
     // Returns: List of users and count of users
-    r.GET("/users", handler.ListUsersHandler)
+    r.GET("/users", h.ListUsersHandler)
 
     // GET /users/search - Get user by email using query parameter
     // Example: /users/search?email=user@example.com
-    r.GET("/users/search", handler.GetUserByEmailHandler)
+    r.GET("/users/search", h.GetUserByEmailHandler)
 
     // GET /users/:id - Get user by their unique ID
     // Example: /users/123e4567-e89b-12d3-a456-426614174000
-    r.GET("/users/:id", handler.GetUserByIDHandler)
+    r.GET("/users/:id", h.GetUserByIDHandler)
 
     // Protected routes for Goals
     protected := r.Group("/")
     protected.Use(middleware.AuthMiddleware())
     {
-        protected.POST("/goals", handler.CreateGoalHandler)
-        protected.GET("/goals", handler.GetGoalsHandler)
-        protected.PUT("/goals/:id/progress", handler.UpdateGoalProgressHandler)
-        protected.DELETE("/goals/:id", handler.DeleteGoalHandler)
+        protected.POST("/goals", h.CreateGoalHandler)
+        protected.GET("/goals", h.GetGoalsHandler)
+        protected.PUT("/goals/:id/progress", h.UpdateGoalProgressHandler)
+        protected.POST("/goals/:id/withdrawals", h.WithdrawGoalHandler)
+        protected.POST("/goals/:id/corrections", h.CorrectGoalHandler)
+        protected.GET("/goals/:id/contributions", h.ListContributionsHandler)
+        protected.GET("/goals/:id/periods", h.ListGoalPeriodsHandler)
+        protected.GET("/goals/:id/milestones", h.ListMilestonesHandler)
+        protected.POST("/allocations", h.AllocateHandler)
+
+        // Goal templates
+        protected.GET("/templates", h.ListTemplatesHandler)
+        protected.POST("/templates", h.CreateTemplateHandler)
+        protected.GET("/templates/:id", h.GetTemplateHandler)
+        protected.DELETE("/templates/:id", h.DeleteTemplateHandler)
+        protected.POST("/goals/from-template/:id", h.CreateGoalFromTemplateHandler)
+
+        // Shared goals: members and invitations
+        protected.GET("/goals/:id/members", h.ListMembersHandler)
+        protected.PATCH("/goals/:id/members/:user_id", h.UpdateMemberHandler)
+        protected.DELETE("/goals/:id/members/:user_id", h.RemoveMemberHandler)
+        protected.POST("/goals/:id/invitations", h.CreateInvitationHandler)
+        protected.GET("/goals/:id/invitations", h.ListGoalInvitationsHandler)
+        protected.DELETE("/goals/:id/invitations/:invitation_id", h.RevokeInvitationHandler)
+        protected.POST("/invitations/:id/accept", h.AcceptInvitationHandler)
+        protected.POST("/invitations/:id/decline", h.DeclineInvitationHandler)
+
+        protected.GET("/goals/:id", h.GetGoalHandler)
+        protected.PATCH("/goals/:id", h.UpdateGoalHandler)
+        protected.DELETE("/goals/:id", h.DeleteGoalHandler)
+
+        // Goal life cycle: pausing, archiving and restoring deleted goals
+        protected.POST("/goals/:id/pause", h.PauseGoalHandler)
+        protected.POST("/goals/:id/resume", h.ResumeGoalHandler)
+        protected.POST("/goals/:id/archive", h.ArchiveGoalHandler)
+        protected.POST("/goals/:id/unarchive", h.UnarchiveGoalHandler)
+        protected.POST("/goals/:id/restore", h.RestoreGoalHandler)
+
+        // The authenticated user's goal categories
+        protected.GET("/categories", h.ListCategoriesHandler)
+        protected.POST("/categories", h.CreateCategoryHandler)
+        protected.GET("/categories/:id", h.GetCategoryHandler)
+        protected.PUT("/categories/:id", h.UpdateCategoryHandler)
+        protected.DELETE("/categories/:id", h.DeleteCategoryHandler)
+
+        // The authenticated user's own settings and totals
+        protected.GET("/me", h.GetMeHandler)
+        protected.PATCH("/me", h.UpdateMeHandler)
+        protected.GET("/me/totals", h.GetTotalsHandler)
+        protected.GET("/me/streaks", h.GetStreaksHandler)
+        protected.GET("/me/analytics", h.GetAnalyticsHandler)
+        protected.GET("/me/invitations", h.ListMyInvitationsHandler)
+
+        // GET /exchange-rates - The table used to convert totals between currencies
+        protected.GET("/exchange-rates", h.GetExchangeRatesHandler)
+    }
+
+    // Admin routes: only users listed in ADMIN_EMAILS may manage exchange rates
+    admin := r.Group("/admin")
+    admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware(h.IsAdmin))
+    {
+        admin.PUT("/exchange-rates", h.ReplaceExchangeRatesHandler)
+        admin.POST("/exchange-rates/reload", h.ReloadExchangeRatesHandler)
     }
 
     // Return the configured router so it can be used to start the HTTP server.
@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go-api-server/internal/database"
+)
+
+// Purge removes deleted goals for good once they have been deleted for longer
+// than the retention window. Until then they can be restored.
+type Purge struct {
+	store     database.Store
+	retention time.Duration
+	interval  time.Duration
+
+	// now returns the current time; replaceable so a pass can be run "in the future"
+	now func() time.Time
+}
+
+// NewPurge creates a purge job that checks the store every interval and
+// removes the goals deleted more than retention ago.
+func NewPurge(store database.Store, retention, interval time.Duration) *Purge {
+	return &Purge{store: store, retention: retention, interval: interval, now: time.Now}
+}
+
+// Run does a pass right away and then one every interval, until ctx is cancelled.
+func (p *Purge) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := p.RunOnce(p.now()); err != nil {
+			log.Printf("purge: %v", err)
+		} else if purged > 0 {
+			log.Printf("purge: removed %d deleted goals", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce removes the goals deleted before now minus the retention window
+// and returns how many were removed.
+func (p *Purge) RunOnce(now time.Time) (int, error) {
+	return p.store.PurgeDeletedGoals(now.Add(-p.retention))
+}
@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-api-server/internal/database"
+	"go-api-server/internal/models"
+)
+
+func TestPurgeRemovesGoalsPastRetention(t *testing.T) {
+	const retention = 30 * 24 * time.Hour
+	db := database.NewInMemoryDB()
+	now := monday.AddDate(0, 2, 0)
+
+	deletedAt := map[string]time.Time{
+		"expired":  now.Add(-retention - time.Hour),
+		"retained": now.Add(-retention + time.Hour),
+	}
+	for _, id := range []string{"expired", "retained", "live"} {
+		createGoal(t, db, &models.Goal{
+			ID: id, Duration: models.Monthly, Period: 1,
+			StartDate: monday, EndDate: monday.AddDate(0, 1, 0),
+		})
+		at, deleted := deletedAt[id]
+		if !deleted {
+			continue
+		}
+		_, err := db.UpdateGoalFunc(id, func(goal *models.Goal) error { return goal.Delete(at) })
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	job := NewPurge(db, retention, time.Hour)
+	job.now = func() time.Time { return now }
+	runPass(job)
+
+	if _, err := db.GetGoalByID("expired"); !errors.Is(err, database.ErrGoalNotFound) {
+		t.Errorf("goal deleted before the retention window: %v, want %v", err, database.ErrGoalNotFound)
+	}
+	for _, id := range []string{"retained", "live"} {
+		if _, err := db.GetGoalByID(id); err != nil {
+			t.Errorf("goal %q: %v", id, err)
+		}
+	}
+
+	// Two hours later the retained goal has been deleted for longer than retention too
+	if purged, err := job.RunOnce(now.Add(2 * time.Hour)); err != nil || purged != 1 {
+		t.Errorf("next pass purged %d goals (%v), want 1", purged, err)
+	}
+	if _, err := db.GetGoalByID("live"); err != nil {
+		t.Errorf("goal %q: %v", "live", err)
+	}
+}
@@ -0,0 +1,93 @@
+// Package scheduler runs background jobs inside the server process.
+//
+// Jobs are plain loops driven by a time.Ticker and stopped through a
+// context. Each job also exposes a RunOnce method that does a single pass at
+// a given time, which is what the loop calls on every tick.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go-api-server/internal/database"
+)
+
+// Rollover starts the next period of recurring goals whose period has ended,
+// archiving the finished period with its final amount and outcome.
+type Rollover struct {
+	store    database.Store
+	interval time.Duration
+
+	// now returns the current time; replaceable so a pass can be run "in the future"
+	now func() time.Time
+}
+
+// NewRollover creates a rollover job that checks the store every interval.
+func NewRollover(store database.Store, interval time.Duration) *Rollover {
+	return &Rollover{store: store, interval: interval, now: time.Now}
+}
+
+// Run does a pass right away (to catch up on periods that ended while the
+// server was down) and then one every interval, until ctx is cancelled.
+func (r *Rollover) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RunOnce(r.now()); err != nil {
+			log.Printf("rollover: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce rolls over every recurring goal whose period ended by now and
+// returns how many periods were archived. A goal that fails is logged and
+// skipped, so one bad goal can't hold back the others; the next pass retries it.
+func (r *Rollover) RunOnce(now time.Time) (int, error) {
+	due, err := r.store.ListRolloverDue(now)
+	if err != nil {
+		return 0, err
+	}
+
+	// New periods follow the owner's calendar; look each owner up once per pass
+	zones := map[string]*time.Location{}
+	archived := 0
+	for _, goal := range due {
+		loc, ok := zones[goal.UserID]
+		if !ok {
+			loc = r.ownerLocation(goal.UserID)
+			zones[goal.UserID] = loc
+		}
+
+		_, periods, err := r.store.RollOverGoal(goal.ID, now, loc)
+		if errors.Is(err, database.ErrGoalNotFound) {
+			// Deleted since we listed it
+			continue
+		}
+		if err != nil {
+			log.Printf("rollover: goal %s: %v", goal.ID, err)
+			continue
+		}
+		archived += len(periods)
+	}
+	return archived, nil
+}
+
+// ownerLocation returns the time zone of the user with userID,
+// or UTC if the user can't be loaded.
+func (r *Rollover) ownerLocation(userID string) *time.Location {
+	user, err := r.store.GetUserByID(userID)
+	if err != nil {
+		log.Printf("rollover: user %s: %v (using UTC)", userID, err)
+		return time.UTC
+	}
+	return user.Location()
+}
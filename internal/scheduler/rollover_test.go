@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-api-server/internal/database"
+	"go-api-server/internal/models"
+)
+
+// monday is the start of the test goals' first period.
+var monday = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// createGoal stores goal for user-1 with a 100.00 USD target.
+func createGoal(t *testing.T, db database.Store, goal *models.Goal) {
+	t.Helper()
+	goal.UserID = "user-1"
+	goal.Title = "Goal " + goal.ID
+	goal.TargetAmount = models.NewMoney(100_00, "USD")
+	if goal.CurrentAmount.Currency == "" {
+		goal.CurrentAmount = models.NewMoney(0, "USD")
+	}
+	goal.CreatedAt = goal.StartDate
+	if err := db.CreateGoal(goal); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runPass does a single pass of a job's Run loop: the context is cancelled
+// up front, so Run returns after the pass it does right away.
+func runPass(job interface{ Run(context.Context) }) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	job.Run(ctx)
+}
+
+func TestRolloverArchivesEndedPeriods(t *testing.T) {
+	db := database.NewInMemoryDB()
+	if err := db.CreateUser(&models.User{ID: "user-1", Email: "user-1@example.com", TimeZone: "UTC"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two weekly periods end (Jan 8 and Jan 15) before the pass on Jan 15
+	createGoal(t, db, &models.Goal{
+		ID: "weekly", Duration: models.Weekly, Recurring: true, Period: 1,
+		StartDate: monday, EndDate: monday.AddDate(0, 0, 7),
+		CurrentAmount: models.NewMoney(40_00, "USD"),
+	})
+	// Still in its first period
+	createGoal(t, db, &models.Goal{
+		ID: "monthly", Duration: models.Monthly, Recurring: true, Period: 1,
+		StartDate: monday, EndDate: monday.AddDate(0, 1, 0),
+	})
+	// Ended, but doesn't recur
+	createGoal(t, db, &models.Goal{
+		ID: "once", Duration: models.Weekly, Period: 1,
+		StartDate: monday, EndDate: monday.AddDate(0, 0, 7),
+	})
+
+	job := NewRollover(db, time.Hour)
+	now := monday.AddDate(0, 0, 14).Add(time.Hour)
+	job.now = func() time.Time { return now }
+	runPass(job)
+
+	periods, total, err := db.ListGoalPeriods("weekly", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 {
+		t.Fatalf("archived %d periods of the weekly goal, want 2", total)
+	}
+	// Newest first
+	second, first := periods[0], periods[1]
+	if first.Number != 1 || !first.StartDate.Equal(monday) || !first.EndDate.Equal(monday.AddDate(0, 0, 7)) ||
+		first.FinalAmount.Minor != 40_00 || first.Succeeded {
+		t.Errorf("first period = %+v", first)
+	}
+	if second.Number != 2 || !second.EndDate.Equal(monday.AddDate(0, 0, 14)) || second.FinalAmount.Minor != 0 {
+		t.Errorf("second period = %+v", second)
+	}
+
+	goal, err := db.GetGoalByID("weekly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if goal.Period != 3 || !goal.StartDate.Equal(monday.AddDate(0, 0, 14)) ||
+		!goal.EndDate.Equal(monday.AddDate(0, 0, 21)) || goal.CurrentAmount.Minor != 0 {
+		t.Errorf("current period = %d, %v to %v, %d saved; want 3, Jan 15 to Jan 22, 0 saved",
+			goal.Period, goal.StartDate, goal.EndDate, goal.CurrentAmount.Minor)
+	}
+
+	for _, id := range []string{"monthly", "once"} {
+		if _, total, _ := db.ListGoalPeriods(id, 10, 0); total != 0 {
+			t.Errorf("goal %q archived %d periods, want 0", id, total)
+		}
+		if goal, _ := db.GetGoalByID(id); goal.Period != 1 {
+			t.Errorf("goal %q is in period %d, want 1", id, goal.Period)
+		}
+	}
+
+	// Nothing else is due at the same time
+	if archived, err := job.RunOnce(now); err != nil || archived != 0 {
+		t.Errorf("second pass archived %d periods (%v), want 0", archived, err)
+	}
+}
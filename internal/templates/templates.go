@@ -0,0 +1,85 @@
+// Package templates holds the built-in goal templates, such as "Emergency
+// fund" and "Vacation". They ship with the server in builtin.json and are
+// seeded into the store on startup, so adding or changing a template only
+// takes an edit of that file and a restart.
+package templates
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go-api-server/internal/database"
+	"go-api-server/internal/models"
+)
+
+//go:embed builtin.json
+var builtinJSON []byte
+
+// Builtin parses the built-in templates from builtin.json and checks them.
+// Their amounts have no currency, so they are in each user's default currency.
+func Builtin() ([]*models.GoalTemplate, error) {
+	var templates []*models.GoalTemplate
+	if err := json.Unmarshal(builtinJSON, &templates); err != nil {
+		return nil, fmt.Errorf("builtin.json: %w", err)
+	}
+
+	seen := make(map[string]bool, len(templates))
+	for _, template := range templates {
+		if err := check(template); err != nil {
+			return nil, fmt.Errorf("builtin.json: template %q: %w", template.ID, err)
+		}
+		if seen[template.ID] {
+			return nil, fmt.Errorf("builtin.json: duplicate template %q", template.ID)
+		}
+		seen[template.ID] = true
+
+		if template.Milestones == nil {
+			template.Milestones = []models.MilestoneRequest{}
+		}
+		template.Tags = models.NormalizeTags(template.Tags)
+	}
+	return templates, nil
+}
+
+// check reports what is wrong with a built-in template, if anything.
+func check(template *models.GoalTemplate) error {
+	switch {
+	case template.ID == "":
+		return fmt.Errorf("id is required")
+	case template.UserID != "" || template.Currency != "":
+		return fmt.Errorf("built-in templates can't have a user_id or currency")
+	case template.Name == "" || template.Title == "":
+		return fmt.Errorf("name and title are required")
+	case template.TargetAmount.Currency != "" || template.TargetAmount.Minor <= 0:
+		return fmt.Errorf("target_amount must be a positive number without a currency")
+	case !template.Duration.Valid() || template.Duration == models.Custom:
+		return fmt.Errorf("duration %q must be a fixed duration", template.Duration)
+	case len(template.Milestones) > models.MaxMilestones || len(template.Tags) > models.MaxTags:
+		return fmt.Errorf("too many milestones or tags")
+	}
+	for _, milestone := range template.Milestones {
+		withAmount := milestone.Amount != nil
+		if withAmount == (milestone.Percent != 0) || milestone.Percent < 0 || milestone.Percent > 100 {
+			return fmt.Errorf("milestones need either a percent (1-100) or an amount")
+		}
+		if withAmount && (milestone.Amount.Currency != "" || milestone.Amount.Minor <= 0) {
+			return fmt.Errorf("milestone amounts must be positive numbers without a currency")
+		}
+	}
+	return nil
+}
+
+// Seed loads the built-in templates into store (see database.Store.SeedTemplates).
+// now becomes the creation time of templates that are new.
+func Seed(store database.Store, now time.Time) error {
+	templates, err := Builtin()
+	if err != nil {
+		return err
+	}
+	for _, template := range templates {
+		template.CreatedAt = now
+	}
+	return store.SeedTemplates(templates)
+}